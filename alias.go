@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	dto "github.com/prometheus/client_model/go"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+)
+
+const (
+	interfaceLabelName = "interface"
+	aliasLabelName     = "alias"
+)
+
+// addAliasLabels adds the alias of the link named by the interface label as
+// alias label to every series. Series of links without an alias and series
+// already having an alias label are left as they are.
+func addAliasLabels(metricFamilies []*dto.MetricFamily) error {
+	aliases, err := linkAliases()
+	if err != nil {
+		return err
+	}
+
+	for _, metricFamily := range metricFamilies {
+		for _, metric := range metricFamily.Metric {
+			addAliasLabel(metric, aliases)
+		}
+	}
+	return nil
+}
+
+// addEventAliasLabels adds the alias label to the series checked for events
+// like addAliasLabels does for the exposed series. It implements events.Labeler.
+func addEventAliasLabels(series []map[string]string) error {
+	aliases, err := linkAliases()
+	if err != nil {
+		return err
+	}
+
+	for _, labels := range series {
+		if _, found := labels[aliasLabelName]; found {
+			continue
+		}
+		if alias := aliases[labels[interfaceLabelName]]; alias != "" {
+			labels[aliasLabelName] = alias
+		}
+	}
+	return nil
+}
+
+// linkAliases returns the aliases of all links having one by link name
+func linkAliases() (map[string]string, error) {
+	links, err := sysroot.LinkList()
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not get link list")
+	}
+	aliases := map[string]string{}
+	for _, link := range links {
+		if link.Attrs().Alias != "" {
+			aliases[link.Attrs().Name] = link.Attrs().Alias
+		}
+	}
+	return aliases, nil
+}
+
+func addAliasLabel(metric *dto.Metric, aliases map[string]string) {
+	alias := ""
+	for _, label := range metric.Label {
+		switch label.GetName() {
+		case aliasLabelName:
+			return
+		case interfaceLabelName:
+			alias = aliases[label.GetValue()]
+		}
+	}
+	if alias == "" {
+		return
+	}
+
+	name := aliasLabelName
+	metric.Label = append(metric.Label, &dto.LabelPair{
+		Name:  &name,
+		Value: &alias,
+	})
+	sort.Slice(metric.Label, func(i, j int) bool {
+		return metric.Label[i].GetName() < metric.Label[j].GetName()
+	})
+}
@@ -1,12 +1,14 @@
 package asic
 
 import (
+	"context"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
 )
 
 const prefix string = "cumulus_switchd_"
@@ -56,7 +58,7 @@ type statsCallbackFunc func(metrics chan<- prometheus.Metric) error
 func makeDefaultStatsCallback(metricsDesc *prometheus.Desc, countFile string, maxFile string) statsCallbackFunc {
 	if maxFile == "" {
 		return func(metrics chan<- prometheus.Metric) error {
-			currentValue, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, countFile))
+			currentValue, err := ReadFloat64FromFileSwitchd(statFile(countFile))
 			if err != nil {
 				return errors.Wrapf(err, "Could not read current value from file '%s': %v", countFile, err)
 			}
@@ -66,11 +68,11 @@ func makeDefaultStatsCallback(metricsDesc *prometheus.Desc, countFile string, ma
 		}
 	}
 	return func(metrics chan<- prometheus.Metric) error {
-		currentValue, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, countFile))
+		currentValue, err := ReadFloat64FromFileSwitchd(statFile(countFile))
 		if err != nil {
 			return errors.Wrapf(err, "Could not read current value from file '%s': %v", countFile, err)
 		}
-		maxValue, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, maxFile))
+		maxValue, err := ReadFloat64FromFileSwitchd(statFile(maxFile))
 		if err != nil {
 			return errors.Wrapf(err, "Could not read max value from file '%s': %v", maxFile, err)
 		}
@@ -83,15 +85,15 @@ func makeDefaultStatsCallback(metricsDesc *prometheus.Desc, countFile string, ma
 
 func makeDefaultStatsCallbackAllocations(metricsDesc *prometheus.Desc, countFile string, maxFile string, allocationsFile string) statsCallbackFunc {
 	return func(metrics chan<- prometheus.Metric) error {
-		currentValue, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, countFile))
+		currentValue, err := ReadFloat64FromFileSwitchd(statFile(countFile))
 		if err != nil {
 			return errors.Wrapf(err, "Could not read current value from file '%s': %v", countFile, err)
 		}
-		maxValue, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, maxFile))
+		maxValue, err := ReadFloat64FromFileSwitchd(statFile(maxFile))
 		if err != nil {
 			return errors.Wrapf(err, "Could not read max value from file '%s': %v", maxFile, err)
 		}
-		allocatedValue, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, allocationsFile))
+		allocatedValue, err := ReadFloat64FromFileSwitchd(statFile(allocationsFile))
 		if err != nil {
 			return errors.Wrapf(err, "Could not read allocated value from file '%s': %v", allocationsFile, err)
 		}
@@ -178,6 +180,11 @@ func makeStats(routeMode int, hostMode int) []statsCallbackFunc {
 	}
 }
 
+// statFile returns the path of a file in switchd's stat directory
+func statFile(name string) string {
+	return filepath.Join(sysroot.Path(statPath), name)
+}
+
 // Collector collects metrics exposed by switchd in the /cumulus/switchd fuse
 type Collector struct{}
 
@@ -192,6 +199,10 @@ func (*Collector) Name() string {
 }
 
 func init() {
+	collector.Register("asic", false, nil, func(collector.Config) (collector.Collector, error) {
+		return NewCollector(), nil
+	})
+
 	labels := []string{"reading_type"}
 	host0EntriesDesc = prometheus.NewDesc(prefix+"host_0_entry", "Host 0 entries", labels, nil)
 	host1EntriesDesc = prometheus.NewDesc(prefix+"host_1_entry", "Host 0 entries", labels, nil)
@@ -272,21 +283,17 @@ func (*Collector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func getRouteMode() (int, error) {
-	routeMode, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, "route_info/route/mode"))
+	routeMode, err := ReadFloat64FromFileSwitchd(statFile("route_info/route/mode"))
 	return int(routeMode), err
 }
 
 func getHostMode() (int, error) {
-	hostMode, err := ReadFloat64FromFileSwitchd(filepath.Join(statPath, "route_info/host/mode"))
+	hostMode, err := ReadFloat64FromFileSwitchd(statFile("route_info/host/mode"))
 	return int(hostMode), err
 }
 
 // Collect implements collector.Collector interface's Collect function
-func (c *Collector) Collect(metrics chan<- prometheus.Metric, errorChan chan error, done chan struct{}) {
-	defer func() {
-		done <- struct{}{}
-	}()
-
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
 	routeMode, err := getRouteMode()
 	if err != nil {
 		errorChan <- errors.Wrapf(err, "Could not retrieve route mode: %v", err)
@@ -301,6 +308,9 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric, errorChan chan err
 
 	log.Infof("len(stats) = %d", len(stats))
 	for _, stat := range stats {
+		if ctx.Err() != nil {
+			return
+		}
 		err := stat(metrics)
 		if err != nil {
 			errorChan <- err
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// start runs the collector in the background if it has an interval
+func (c *enabledCollector) start() {
+	if c.interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	log.Infof("Running %s collector in the background every %s", c.name, c.interval)
+	go c.runInBackground(ctx)
+}
+
+// stop ends the background collection started by start and releases the
+// collector's resources
+func (c *enabledCollector) stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if closer, ok := c.Collector.(collector.Closer); ok {
+		closer.Close()
+	}
+}
+
+func (c *enabledCollector) runInBackground(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		result := collect(ctx, c)
+		if ctx.Err() != nil {
+			return
+		}
+		c.resultLock.Lock()
+		c.result = result
+		c.resultLock.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lastResult returns the result of the last background run or nil
+func (c *enabledCollector) lastResult() *collectionResult {
+	c.resultLock.Lock()
+	defer c.resultLock.Unlock()
+	return c.result
+}
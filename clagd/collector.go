@@ -0,0 +1,166 @@
+package clagd
+
+import (
+	"context"
+	"flag"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const prefix = "clagd_"
+
+var (
+	clagctlPath = flag.String("collector.clagd.clagctl-path", "/usr/bin/clagctl", "clagctl binary path")
+
+	peerAliveDesc                *prometheus.Desc
+	roleInfoDesc                 *prometheus.Desc
+	peerRoleInfoDesc             *prometheus.Desc
+	priorityDesc                 *prometheus.Desc
+	peerPriorityDesc             *prometheus.Desc
+	peerInfoDesc                 *prometheus.Desc
+	backupActiveDesc             *prometheus.Desc
+	systemMacInfoDesc            *prometheus.Desc
+	conflictsDesc                *prometheus.Desc
+	conflictInfoDesc             *prometheus.Desc
+	interfaceOperUpDesc          *prometheus.Desc
+	interfacePeerOperUpDesc      *prometheus.Desc
+	interfaceDualConnectedDesc   *prometheus.Desc
+	interfaceStatusInfoDesc      *prometheus.Desc
+	interfaceProtoDownDesc       *prometheus.Desc
+	interfaceProtoDownReasonDesc *prometheus.Desc
+	interfaceConflictInfoDesc    *prometheus.Desc
+)
+
+// Config configures the clagd collector
+type Config struct {
+	collector.Settings `yaml:",inline"`
+	ClagctlPath        string `yaml:"clagctl_path"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	if c.ClagctlPath == "" {
+		return errors.New("clagctl_path must not be empty")
+	}
+	return nil
+}
+
+// Collector collects MLAG metrics exposed by clagctl
+type Collector struct {
+	clagctlPath string
+}
+
+// NewCollector returns a new Collector instance
+func NewCollector(clagctlPath string) *Collector {
+	return &Collector{
+		clagctlPath: clagctlPath,
+	}
+}
+
+func init() {
+	collector.Register("clagd", false, func() collector.Config {
+		return &Config{
+			ClagctlPath: *clagctlPath,
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		return NewCollector(cfg.(*Config).ClagctlPath), nil
+	})
+
+	peerAliveDesc = prometheus.NewDesc(prefix+"peer_alive_bool", "peer alive", nil, nil)
+	roleInfoDesc = prometheus.NewDesc(prefix+"role_info", "our role", []string{"role"}, nil)
+	peerRoleInfoDesc = prometheus.NewDesc(prefix+"peer_role_info", "peer role", []string{"role"}, nil)
+	priorityDesc = prometheus.NewDesc(prefix+"priority", "our priority", nil, nil)
+	peerPriorityDesc = prometheus.NewDesc(prefix+"peer_priority", "peer priority", nil, nil)
+	peerInfoDesc = prometheus.NewDesc(prefix+"peer_info", "our and peer id, peer interface and ip", []string{"our_id", "peer_id", "peer_interface", "peer_ip"}, nil)
+	backupActiveDesc = prometheus.NewDesc(prefix+"backup_active_bool", "backup ip reachable", []string{"backup_ip", "backup_vrf"}, nil)
+	systemMacInfoDesc = prometheus.NewDesc(prefix+"system_mac_info", "system mac", []string{"system_mac"}, nil)
+	conflictsDesc = prometheus.NewDesc(prefix+"conflicts", "number of configuration conflicts with the peer", nil, nil)
+	conflictInfoDesc = prometheus.NewDesc(prefix+"conflict_info", "configuration conflict with the peer", []string{"conflict"}, nil)
+
+	labels := []string{"interface", "peer_interface", "clag_id"}
+	interfaceOperUpDesc = prometheus.NewDesc(prefix+"interface_oper_up_bool", "local bond operationally up", labels, nil)
+	interfacePeerOperUpDesc = prometheus.NewDesc(prefix+"interface_peer_oper_up_bool", "peer bond operationally up", labels, nil)
+	interfaceDualConnectedDesc = prometheus.NewDesc(prefix+"interface_dual_connected_bool", "bond is dual connected", labels, nil)
+	interfaceStatusInfoDesc = prometheus.NewDesc(prefix+"interface_status_info", "sync status of the bond", append(labels, "status"), nil)
+	interfaceProtoDownDesc = prometheus.NewDesc(prefix+"interface_proto_down_bool", "bond is proto down", labels, nil)
+	interfaceProtoDownReasonDesc = prometheus.NewDesc(prefix+"interface_proto_down_reason_info", "proto down reason", append(labels, "reason"), nil)
+	interfaceConflictInfoDesc = prometheus.NewDesc(prefix+"interface_conflict_info", "configuration conflict of the bond with the peer", append(labels, "conflict"), nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- peerAliveDesc
+	ch <- roleInfoDesc
+	ch <- peerRoleInfoDesc
+	ch <- priorityDesc
+	ch <- peerPriorityDesc
+	ch <- peerInfoDesc
+	ch <- backupActiveDesc
+	ch <- systemMacInfoDesc
+	ch <- conflictsDesc
+	ch <- conflictInfoDesc
+	ch <- interfaceOperUpDesc
+	ch <- interfacePeerOperUpDesc
+	ch <- interfaceDualConnectedDesc
+	ch <- interfaceStatusInfoDesc
+	ch <- interfaceProtoDownDesc
+	ch <- interfaceProtoDownReasonDesc
+	ch <- interfaceConflictInfoDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	result, err := ShowStatus(ctx, c.clagctlPath)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve clagd status")
+		return
+	}
+
+	collectStatus(&result.Status, metrics)
+	for name, clagInterface := range result.Interfaces {
+		collectForInterface(name, clagInterface, metrics)
+	}
+}
+
+// Name returns the string "ClagdCollector"
+func (*Collector) Name() string {
+	return "ClagdCollector"
+}
+
+func collectStatus(status *Status, metrics chan<- prometheus.Metric) {
+	metrics <- prometheus.MustNewConstMetric(peerAliveDesc, prometheus.GaugeValue, util.BoolToFloat64(status.PeerAlive))
+	metrics <- prometheus.MustNewConstMetric(roleInfoDesc, prometheus.GaugeValue, 1.0, status.OurRole)
+	metrics <- prometheus.MustNewConstMetric(peerRoleInfoDesc, prometheus.GaugeValue, 1.0, status.PeerRole)
+	metrics <- prometheus.MustNewConstMetric(priorityDesc, prometheus.GaugeValue, status.OurPriority)
+	metrics <- prometheus.MustNewConstMetric(peerPriorityDesc, prometheus.GaugeValue, status.PeerPriority)
+	metrics <- prometheus.MustNewConstMetric(peerInfoDesc, prometheus.GaugeValue, 1.0, status.OurID, status.PeerID, status.PeerIf, status.PeerIP)
+	metrics <- prometheus.MustNewConstMetric(backupActiveDesc, prometheus.GaugeValue, util.BoolToFloat64(status.BackupActive), status.BackupIP, status.BackupVrf)
+	metrics <- prometheus.MustNewConstMetric(systemMacInfoDesc, prometheus.GaugeValue, 1.0, status.SysMac)
+	metrics <- prometheus.MustNewConstMetric(conflictsDesc, prometheus.GaugeValue, float64(len(status.Conflicts)))
+	for _, conflict := range status.Conflicts {
+		metrics <- prometheus.MustNewConstMetric(conflictInfoDesc, prometheus.GaugeValue, 1.0, conflict)
+	}
+}
+
+func collectForInterface(name string, clagInterface *ClagInterface, metrics chan<- prometheus.Metric) {
+	labels := []string{name, clagInterface.PeerIf, formatClagID(clagInterface.ClagID)}
+	metrics <- prometheus.MustNewConstMetric(interfaceOperUpDesc, prometheus.GaugeValue, util.BoolToFloat64(clagInterface.OperState == "up"), labels...)
+	if clagInterface.PeerOperState != "" {
+		metrics <- prometheus.MustNewConstMetric(interfacePeerOperUpDesc, prometheus.GaugeValue, util.BoolToFloat64(clagInterface.PeerOperState == "up"), labels...)
+	}
+	metrics <- prometheus.MustNewConstMetric(interfaceDualConnectedDesc, prometheus.GaugeValue, util.BoolToFloat64(clagInterface.Status == "dual"), labels...)
+	statusLabels := append(labels, clagInterface.Status)
+	metrics <- prometheus.MustNewConstMetric(interfaceStatusInfoDesc, prometheus.GaugeValue, 1.0, statusLabels...)
+	metrics <- prometheus.MustNewConstMetric(interfaceProtoDownDesc, prometheus.GaugeValue, util.BoolToFloat64(len(clagInterface.ProtoDownReason) > 0), labels...)
+	for _, reason := range clagInterface.ProtoDownReason {
+		reasonLabels := append(labels, reason)
+		metrics <- prometheus.MustNewConstMetric(interfaceProtoDownReasonDesc, prometheus.GaugeValue, 1.0, reasonLabels...)
+	}
+	for _, conflict := range clagInterface.Conflicts {
+		conflictLabels := append(labels, conflict)
+		metrics <- prometheus.MustNewConstMetric(interfaceConflictInfoDesc, prometheus.GaugeValue, 1.0, conflictLabels...)
+	}
+}
@@ -0,0 +1,69 @@
+package clagd
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ClagctlResult stores the parsed output of `clagctl -j`
+type ClagctlResult struct {
+	Status     Status                    `json:"status"`
+	Interfaces map[string]*ClagInterface `json:"clagIntfs"`
+}
+
+// Status stores the state of the local clagd and its peer
+type Status struct {
+	PeerAlive    bool       `json:"peerAlive"`
+	OurRole      string     `json:"ourRole"`
+	PeerRole     string     `json:"peerRole"`
+	OurPriority  float64    `json:"ourPriority"`
+	PeerPriority float64    `json:"peerPriority"`
+	OurID        string     `json:"ourId"`
+	PeerID       string     `json:"peerId"`
+	PeerIf       string     `json:"peerIf"`
+	PeerIP       string     `json:"peerIp"`
+	BackupIP     string     `json:"backupIp"`
+	BackupVrf    string     `json:"backupVrf"`
+	BackupActive bool       `json:"backupActive"`
+	SysMac       string     `json:"sysMac"`
+	Conflicts    StringList `json:"conflicts"`
+}
+
+// ClagInterface stores the state of a single dual-connected bond
+type ClagInterface struct {
+	ClagID          float64    `json:"clagId"`
+	OperState       string     `json:"operstate"`
+	PeerIf          string     `json:"peerIf"`
+	PeerOperState   string     `json:"peerOperstate"`
+	Status          string     `json:"status"`
+	Conflicts       StringList `json:"conflicts"`
+	ProtoDownReason StringList `json:"protodownReason"`
+}
+
+// StringList is a list of strings, that clagctl either prints as JSON list or
+// as comma separated string with "-" meaning none
+type StringList []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *StringList) UnmarshalJSON(b []byte) error {
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		*l = list
+		return nil
+	}
+
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	*l = StringList{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || item == "-" {
+			continue
+		}
+		*l = append(*l, item)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package clagd
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+)
+
+// ShowStatus executes and parses "clagctl -j"
+func ShowStatus(ctx context.Context, clagctlPath string) (*ClagctlResult, error) {
+	stdout, stderr, err := sysroot.RunCommand(ctx, clagctlPath, "-j")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Executing '%s -j' failed, stderr reads: %s", clagctlPath, stderr)
+	}
+
+	res := &ClagctlResult{}
+	err = json.Unmarshal(stdout, res)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON unmarshal failed")
+	}
+
+	return res, nil
+}
+
+func formatClagID(clagID float64) string {
+	return strconv.FormatFloat(clagID, 'f', -1, 64)
+}
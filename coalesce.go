@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// scrapeGroup coalesces concurrent scrapes of the same collectors into a
+// single collection run
+var scrapeGroup singleflight.Group
+
+type gatherResult struct {
+	metricFamilies []*dto.MetricFamily
+	err            error
+}
+
+// newCoalescingGatherer returns a gatherer running collectors. Concurrent
+// gatherers for the same set of collectors of the same configuration
+// generation share one run and its result. With aliasLabel the link aliases
+// are added to the result, see addAliasLabels.
+func newCoalescingGatherer(ctx context.Context, collectors []*enabledCollector, aliasLabel bool, generation uint64) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		key := strconv.FormatUint(generation, 10) + ":" + collectorsKey(collectors)
+		if aliasLabel {
+			key += ",alias"
+		}
+		res, _, _ := scrapeGroup.Do(key, func() (interface{}, error) {
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(newCumulusCollector(ctx, collectors))
+			metricFamilies, err := registry.Gather()
+			if aliasLabel {
+				aliasErr := addAliasLabels(metricFamilies)
+				if aliasErr != nil {
+					log.Errorf("Could not add alias labels: %v", aliasErr)
+				}
+			}
+			return &gatherResult{metricFamilies, err}, nil
+		})
+		result := res.(*gatherResult)
+		return result.metricFamilies, result.err
+	})
+}
+
+func collectorsKey(collectors []*enabledCollector) string {
+	names := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		names = append(names, c.name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// blockingCollector counts its collections, which block until released
+type blockingCollector struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (*blockingCollector) Name() string {
+	return "BlockingCollector"
+}
+
+func (*blockingCollector) Describe(ch chan<- *prometheus.Desc) {
+}
+
+func (c *blockingCollector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	c.started <- struct{}{}
+	<-c.release
+}
+
+func TestCoalescingGathererGenerations(t *testing.T) {
+	c := &blockingCollector{
+		started: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+	collectors := []*enabledCollector{{Collector: c, name: "blocking", timeout: 10 * time.Second}}
+
+	var waitGroup sync.WaitGroup
+	gather := func(generation uint64) {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			_, _ = newCoalescingGatherer(context.Background(), collectors, false, generation).Gather()
+		}()
+	}
+
+	gather(1)
+	<-c.started
+	// joins the running collection of generation 1
+	gather(1)
+	// the configuration was reloaded in the meantime
+	gather(2)
+	select {
+	case <-c.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scrape of generation 2 joined the collection of generation 1")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(c.release)
+	waitGroup.Wait()
+	if started := len(c.started); started != 0 {
+		t.Errorf("got %d more collections, want 2 in total", started)
+	}
+}
@@ -1,12 +1,80 @@
 package collector
 
 import (
+	"context"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Collector is an interface, that is implemented by mstpd, asic collector, etc.
+//
+// Collect must return once it has finished collecting or as soon as possible
+// after ctx has been cancelled. Errors that do not abort the collection are
+// reported through errorChan.
 type Collector interface {
+	Name() string
+	Describe(ch chan<- *prometheus.Desc)
+	Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error)
+}
+
+// Closer is implemented by collectors holding resources across collections,
+// e.g. a netlink subscription. Close is called once the collector has been
+// replaced, e.g. after the configuration has been reloaded.
+type Closer interface {
+	Close()
+}
+
+// LegacyCollector is the channel based collector interface, that is still
+// implemented by external collectors like the transceiver-exporter's.
+type LegacyCollector interface {
 	Name() string
 	Describe(ch chan<- *prometheus.Desc)
 	Collect(metrics chan<- prometheus.Metric, errorChan chan error, done chan struct{})
 }
+
+type legacyCollector struct {
+	LegacyCollector
+}
+
+// WrapLegacy adapts a LegacyCollector to the Collector interface
+func WrapLegacy(c LegacyCollector) Collector {
+	return &legacyCollector{c}
+}
+
+// Collect implements the Collector interface by running the wrapped collector
+// in the background until it signals done or ctx is cancelled
+func (c *legacyCollector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	legacyMetrics := make(chan prometheus.Metric)
+	legacyErrors := make(chan error)
+	done := make(chan struct{}, 1)
+
+	go c.LegacyCollector.Collect(legacyMetrics, legacyErrors, done)
+
+	for {
+		select {
+		case metric := <-legacyMetrics:
+			metrics <- metric
+		case err := <-legacyErrors:
+			errorChan <- err
+		case <-done:
+			return
+		case <-ctx.Done():
+			// the wrapped collector can not be cancelled, keep draining its
+			// channels so it does not leak once it eventually finishes
+			go Drain(legacyMetrics, legacyErrors, done)
+			return
+		}
+	}
+}
+
+// Drain consumes the output of an abandoned collector until it signals done
+func Drain(metrics <-chan prometheus.Metric, errorChan <-chan error, done <-chan struct{}) {
+	for {
+		select {
+		case <-metrics:
+		case <-errorChan:
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package collector
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Settings holds the options every collector has, independent of its kind
+type Settings struct {
+	Enabled bool          `yaml:"enabled"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Interval enables background collection, scrapes are then served
+	// from the result of the last run
+	Interval time.Duration `yaml:"interval"`
+}
+
+// GetSettings implements the Config interface
+func (s *Settings) GetSettings() *Settings {
+	return s
+}
+
+// Config is implemented by the configuration of every collector. Collector
+// specific configurations embed Settings inline.
+type Config interface {
+	GetSettings() *Settings
+}
+
+// Validator may be implemented by a Config to reject invalid options
+type Validator interface {
+	Validate() error
+}
+
+// Factory creates a new collector instance from its configuration
+type Factory func(cfg Config) (Collector, error)
+
+// Registration describes a collector registered through Register
+type Registration struct {
+	Name           string
+	DefaultEnabled bool
+
+	newConfig func() Config
+	factory   Factory
+	enabled   *bool
+	timeout   *time.Duration
+	interval  *time.Duration
+}
+
+var (
+	registrations     = map[string]*Registration{}
+	registrationsLock = &sync.Mutex{}
+
+	// legacyCollectors were toggled with -collectors.<name> before collectors
+	// registered themselves, the flag is kept as a deprecated alias for them
+	legacyCollectors = map[string]bool{
+		"asic":        true,
+		"hwmon":       true,
+		"mstpd":       true,
+		"transceiver": true,
+	}
+)
+
+// Register makes a collector available under name. It is meant to be called
+// from the collector package's init function and registers the flags
+// -collector.<name>, -no-collector.<name>, -collector.<name>.timeout and
+// -collector.<name>.interval. Collector specific options are registered by the
+// collector package as -collector.<name>.<option>.
+// newConfig returns the collector's configuration as given by its own flags,
+// it may be nil if the collector has no options besides Settings.
+func Register(name string, defaultEnabled bool, newConfig func() Config, factory Factory) {
+	registrationsLock.Lock()
+	defer registrationsLock.Unlock()
+
+	if _, exists := registrations[name]; exists {
+		panic(fmt.Sprintf("collector %s registered twice", name))
+	}
+	if newConfig == nil {
+		newConfig = func() Config {
+			return &Settings{}
+		}
+	}
+
+	enabled := defaultEnabled
+	defaultState := "disabled"
+	if defaultEnabled {
+		defaultState = "enabled"
+	}
+	flag.Var(&enableFlag{value: &enabled, enable: true}, "collector."+name, fmt.Sprintf("Enable the %s collector (default: %s)", name, defaultState))
+	flag.Var(&enableFlag{value: &enabled, enable: false}, "no-collector."+name, fmt.Sprintf("Disable the %s collector", name))
+	if legacyCollectors[name] {
+		DeprecatedAlias("collectors."+name, "collector."+name)
+	}
+
+	registrations[name] = &Registration{
+		Name:           name,
+		DefaultEnabled: defaultEnabled,
+		newConfig:      newConfig,
+		factory:        factory,
+		enabled:        &enabled,
+		timeout:        flag.Duration("collector."+name+".timeout", 0, fmt.Sprintf("%s collector timeout (defaults to collectors.timeout)", name)),
+		interval:       flag.Duration("collector."+name+".interval", 0, fmt.Sprintf("Run the %s collector in the background at this interval (defaults to collectors.interval)", name)),
+	}
+}
+
+// DeprecatedAlias registers the flag alias sharing the value of the already
+// registered flag name. It keeps flags working that were renamed.
+func DeprecatedAlias(alias, name string) {
+	f := flag.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("alias %s of unknown flag %s", alias, name))
+	}
+	flag.Var(f.Value, alias, fmt.Sprintf("Deprecated: alias of -%s", name))
+}
+
+// Registrations returns all registered collectors ordered by name
+func Registrations() []*Registration {
+	registrationsLock.Lock()
+	defer registrationsLock.Unlock()
+
+	res := make([]*Registration, 0, len(registrations))
+	for _, registration := range registrations {
+		res = append(res, registration)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// Lookup returns the registration of the collector name or nil
+func Lookup(name string) *Registration {
+	registrationsLock.Lock()
+	defer registrationsLock.Unlock()
+	return registrations[name]
+}
+
+// NewConfig returns the collector's configuration as given by the command line flags
+func (r *Registration) NewConfig() Config {
+	cfg := r.newConfig()
+	settings := cfg.GetSettings()
+	settings.Enabled = *r.enabled
+	settings.Timeout = *r.timeout
+	settings.Interval = *r.interval
+	return cfg
+}
+
+// New creates a new collector instance from cfg
+func (r *Registration) New(cfg Config) (Collector, error) {
+	return r.factory(cfg)
+}
+
+// enableFlag is a boolean flag that sets value to enable if given, or to the
+// opposite if given as false (e.g. -no-collector.<name>=false)
+type enableFlag struct {
+	value  *bool
+	enable bool
+}
+
+func (f *enableFlag) String() string {
+	return ""
+}
+
+func (f *enableFlag) Set(s string) error {
+	given, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*f.value = given == f.enable
+	return nil
+}
+
+func (f *enableFlag) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestEnableFlags(t *testing.T) {
+	legacyCollectors["disabled_test"] = true
+	legacyCollectors["enabled_test"] = true
+	Register("disabled_test", false, nil, nil)
+	Register("enabled_test", true, nil, nil)
+
+	tests := []struct {
+		name    string
+		args    []string
+		enabled bool
+	}{
+		{name: "disabled_test", args: nil, enabled: false},
+		{name: "enabled_test", args: nil, enabled: true},
+		{name: "disabled_test", args: []string{"-collector.disabled_test"}, enabled: true},
+		{name: "disabled_test", args: []string{"-collector.disabled_test=true"}, enabled: true},
+		{name: "enabled_test", args: []string{"-collector.enabled_test=false"}, enabled: false},
+		{name: "enabled_test", args: []string{"-no-collector.enabled_test"}, enabled: false},
+		{name: "enabled_test", args: []string{"-no-collector.enabled_test=true"}, enabled: false},
+		{name: "disabled_test", args: []string{"-no-collector.disabled_test=false"}, enabled: true},
+		{name: "disabled_test", args: []string{"-collectors.disabled_test"}, enabled: true},
+		{name: "disabled_test", args: []string{"-collectors.disabled_test=true"}, enabled: true},
+		{name: "enabled_test", args: []string{"-collectors.enabled_test=false"}, enabled: false},
+		{name: "disabled_test", args: []string{"-collector.disabled_test", "-no-collector.disabled_test"}, enabled: false},
+		{name: "enabled_test", args: []string{"-no-collector.enabled_test", "-collector.enabled_test"}, enabled: true},
+	}
+
+	for _, test := range tests {
+		registration := Lookup(test.name)
+		*registration.enabled = registration.DefaultEnabled
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		for _, name := range []string{"collector." + test.name, "no-collector." + test.name, "collectors." + test.name} {
+			flags.Var(flag.Lookup(name).Value, name, "")
+		}
+		err := flags.Parse(test.args)
+		if err != nil {
+			t.Fatalf("parsing %v failed: %v", test.args, err)
+		}
+		enabled := registration.NewConfig().GetSettings().Enabled
+		if enabled != test.enabled {
+			t.Errorf("%v: got enabled %t, want %t", test.args, enabled, test.enabled)
+		}
+	}
+}
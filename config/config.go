@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/events"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the exporter's configuration, built from the command line flags
+// and optionally the --config.file YAML file
+type Config struct {
+	// Timeout is used for collectors not having a timeout of their own
+	Timeout time.Duration
+	// Interval is used for collectors not having an interval of their own,
+	// 0 disables background collection
+	Interval time.Duration
+	// InterfaceAliasLabel adds the link alias as alias label to all series
+	// having an interface label
+	InterfaceAliasLabel bool
+	// Collectors maps the name of every registered collector to its configuration
+	Collectors map[string]collector.Config
+	// Events configures the events detected in the collected metrics, it is
+	// only given by the configuration file
+	Events *events.Config
+}
+
+// file is the layout of the YAML configuration file. Every collector has its
+// own section named after it below collectors.
+type file struct {
+	Collectors struct {
+		Timeout             *time.Duration       `yaml:"timeout"`
+		Interval            *time.Duration       `yaml:"interval"`
+		InterfaceAliasLabel *bool                `yaml:"interface_alias_label"`
+		Sections            map[string]yaml.Node `yaml:",inline"`
+	} `yaml:"collectors"`
+	Events *events.Config `yaml:"events"`
+}
+
+// FromFlags returns the configuration given by the command line flags
+func FromFlags(timeout time.Duration, interval time.Duration, interfaceAliasLabel bool) *Config {
+	cfg := &Config{
+		Timeout:             timeout,
+		Interval:            interval,
+		InterfaceAliasLabel: interfaceAliasLabel,
+		Collectors:          map[string]collector.Config{},
+	}
+	for _, registration := range collector.Registrations() {
+		cfg.Collectors[registration.Name] = registration.NewConfig()
+	}
+	return cfg
+}
+
+// Load reads the YAML file filename on top of cfg, usually the configuration
+// given by the command line flags, and validates the result
+func Load(filename string, cfg *Config) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not read config file '%s'", filename)
+	}
+
+	var f file
+	err = decodeStrict(data, &f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not parse config file '%s'", filename)
+	}
+
+	if f.Collectors.Timeout != nil {
+		cfg.Timeout = *f.Collectors.Timeout
+	}
+	if f.Collectors.Interval != nil {
+		cfg.Interval = *f.Collectors.Interval
+	}
+	if f.Collectors.InterfaceAliasLabel != nil {
+		cfg.InterfaceAliasLabel = *f.Collectors.InterfaceAliasLabel
+	}
+	cfg.Events = f.Events
+	for name, section := range f.Collectors.Sections {
+		collectorConfig, found := cfg.Collectors[name]
+		if !found {
+			return nil, errors.Errorf("Invalid config file '%s': unknown collector %s", filename, name)
+		}
+		// re-encoding the section is the only way to decode a yaml.Node
+		// while rejecting unknown fields
+		sectionData, err := yaml.Marshal(&section)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse section of collector %s in config file '%s'", name, filename)
+		}
+		err = decodeStrict(sectionData, collectorConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse section of collector %s in config file '%s'", name, filename)
+		}
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid config file '%s'", filename)
+	}
+	return cfg, nil
+}
+
+func decodeStrict(data []byte, out interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(out)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate checks the configuration for errors
+func (c *Config) Validate() error {
+	if c.Timeout <= 0 {
+		return errors.New("collectors.timeout must be positive")
+	}
+	if c.Interval < 0 {
+		return errors.New("collectors.interval must not be negative")
+	}
+
+	if c.Events != nil {
+		err := c.Events.Validate()
+		if err != nil {
+			return errors.Wrap(err, "Invalid events configuration")
+		}
+	}
+
+	for name, collectorConfig := range c.Collectors {
+		if !collectorConfig.GetSettings().Enabled {
+			continue
+		}
+		validator, ok := collectorConfig.(collector.Validator)
+		if !ok {
+			continue
+		}
+		err := validator.Validate()
+		if err != nil {
+			return errors.Wrapf(err, "Invalid configuration of collector %s", name)
+		}
+	}
+	return nil
+}
@@ -1,51 +1,181 @@
 package main
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
 
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
 
-type cumulusCollector struct{}
+const metaPrefix = "cumulus_exporter_"
 
-func newCumulusCollector() *cumulusCollector {
-	return &cumulusCollector{}
+// error kinds used as label on the collector error counter
+const (
+	errorKindCollect   = "collect"
+	errorKindTimeout   = "timeout"
+	errorKindCancelled = "cancelled"
+)
+
+var (
+	collectorDurationDesc *prometheus.Desc
+	collectorSuccessDesc  *prometheus.Desc
+	collectorCacheAgeDesc *prometheus.Desc
+	collectorErrors       *prometheus.CounterVec
+)
+
+func init() {
+	labels := []string{"collector"}
+	collectorDurationDesc = prometheus.NewDesc(metaPrefix+"collector_duration_seconds", "Duration of a collector's last scrape in seconds", labels, nil)
+	collectorSuccessDesc = prometheus.NewDesc(metaPrefix+"collector_success", "Whether a collector's last scrape succeeded. 1 = success, 0 = failure", labels, nil)
+	collectorCacheAgeDesc = prometheus.NewDesc(metaPrefix+"collector_cache_age_seconds", "Age of the served result of a collector running in the background in seconds", labels, nil)
+	collectorErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: metaPrefix + "collector_errors_total",
+		Help: "Number of errors a collector ran into, partitioned by kind",
+	}, []string{"collector", "kind"})
+}
+
+// enabledCollector is a collector.Collector together with the name it was
+// registered with, the time it may take before it is abandoned and the
+// interval it runs at in the background
+type enabledCollector struct {
+	collector.Collector
+	name     string
+	timeout  time.Duration
+	interval time.Duration
+
+	cancel     context.CancelFunc
+	result     *collectionResult
+	resultLock sync.Mutex
+}
+
+// collectionResult is the outcome of a single collector run
+type collectionResult struct {
+	metrics   []prometheus.Metric
+	success   bool
+	duration  time.Duration
+	timestamp time.Time
+}
+
+type cumulusCollector struct {
+	ctx        context.Context
+	collectors []*enabledCollector
+}
+
+func newCumulusCollector(ctx context.Context, collectors []*enabledCollector) *cumulusCollector {
+	return &cumulusCollector{
+		ctx:        ctx,
+		collectors: collectors,
+	}
 }
 
-func (*cumulusCollector) Describe(ch chan<- *prometheus.Desc) {
-	for _, collector := range enabledCollectors {
+func (c *cumulusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collectorDurationDesc
+	ch <- collectorSuccessDesc
+	ch <- collectorCacheAgeDesc
+	collectorErrors.Describe(ch)
+	for _, collector := range c.collectors {
 		collector.Describe(ch)
 	}
 }
 
-func (*cumulusCollector) Collect(ch chan<- prometheus.Metric) {
+func (c *cumulusCollector) Collect(ch chan<- prometheus.Metric) {
 	waitGroup := &sync.WaitGroup{}
-	waitGroup.Add(len(enabledCollectors))
+	waitGroup.Add(len(c.collectors))
 
-	for _, collector := range enabledCollectors {
-		go runCollector(collector, waitGroup, ch)
+	for _, collector := range c.collectors {
+		go runCollector(c.ctx, collector, waitGroup, ch)
 	}
 
 	waitGroup.Wait()
+	collectorErrors.Collect(ch)
 }
 
-func runCollector(collector collector.Collector, waitGroup *sync.WaitGroup, ch chan<- prometheus.Metric) {
+func runCollector(ctx context.Context, c *enabledCollector, waitGroup *sync.WaitGroup, ch chan<- prometheus.Metric) {
 	defer waitGroup.Done()
 
+	var result *collectionResult
+	if c.interval > 0 {
+		result = c.lastResult()
+		if result == nil {
+			// the first background run has not finished yet
+			ch <- prometheus.MustNewConstMetric(collectorSuccessDesc, prometheus.GaugeValue, 0, c.name)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(collectorCacheAgeDesc, prometheus.GaugeValue, time.Since(result.timestamp).Seconds(), c.name)
+	} else {
+		result = collect(ctx, c)
+	}
+
+	for _, metric := range result.metrics {
+		ch <- metric
+	}
+	ch <- prometheus.MustNewConstMetric(collectorDurationDesc, prometheus.GaugeValue, result.duration.Seconds(), c.name)
+	ch <- prometheus.MustNewConstMetric(collectorSuccessDesc, prometheus.GaugeValue, util.BoolToFloat64(result.success), c.name)
+}
+
+// collect runs a single collector. Its metrics are discarded if it had to be
+// abandoned and the result is not successful if the collector reported an error.
+func collect(ctx context.Context, c *enabledCollector) *collectionResult {
+	start := time.Now()
+	result := &collectionResult{
+		timestamp: start,
+	}
+	defer func() {
+		result.duration = time.Since(start)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	metricsChan := make(chan prometheus.Metric)
 	errorChan := make(chan error)
 	doneChan := make(chan struct{})
 
-	go collector.Collect(ch, errorChan, doneChan)
+	go func() {
+		defer close(doneChan)
+		c.Collect(ctx, metricsChan, errorChan)
+	}()
 
+	// metrics are buffered until the collector finished, so that a collector
+	// running into its timeout does not leave a partial result behind
+	var metrics []prometheus.Metric
+	success := true
 	for {
 		select {
+		case metric := <-metricsChan:
+			metrics = append(metrics, metric)
 		case err := <-errorChan:
-			log.Errorf("Error running collector %s: %v", collector.Name(), err)
+			log.Errorf("Error running collector %s: %v", c.name, err)
+			collectorErrors.WithLabelValues(c.name, errorKindCollect).Inc()
+			success = false
 		case <-doneChan:
-			return
+			if ctx.Err() != nil {
+				abandonCollector(ctx, c)
+				return result
+			}
+			result.metrics = metrics
+			result.success = success
+			getEventManager().Observe(c.name, metrics)
+			return result
+		case <-ctx.Done():
+			abandonCollector(ctx, c)
+			go collector.Drain(metricsChan, errorChan, doneChan)
+			return result
 		}
 	}
 }
+
+func abandonCollector(ctx context.Context, c *enabledCollector) {
+	log.Errorf("Collector %s abandoned, discarding its metrics: %v", c.name, ctx.Err())
+	kind := errorKindCancelled
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		kind = errorKindTimeout
+	}
+	collectorErrors.WithLabelValues(c.name, kind).Inc()
+}
@@ -0,0 +1,118 @@
+package devlink
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+)
+
+const prefix = "devlink_"
+
+var (
+	trapPacketsDesc        *prometheus.Desc
+	trapBytesDesc          *prometheus.Desc
+	trapGroupPacketsDesc   *prometheus.Desc
+	trapGroupBytesDesc     *prometheus.Desc
+	trapPolicerDroppedDesc *prometheus.Desc
+	trapPolicerRateDesc    *prometheus.Desc
+	trapPolicerBurstDesc   *prometheus.Desc
+)
+
+// Collector collects devlink trap statistics, i.e. the reasons the ASIC
+// dropped or trapped packets
+type Collector struct {
+}
+
+// NewCollector returns a new Collector instance
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+func init() {
+	collector.Register("devlink", false, nil, func(collector.Config) (collector.Collector, error) {
+		return NewCollector(), nil
+	})
+
+	trapLabels := []string{"device", "trap", "group", "action", "type"}
+	trapPacketsDesc = prometheus.NewDesc(prefix+"trap_packets_total", "packets hit by the trap", trapLabels, nil)
+	trapBytesDesc = prometheus.NewDesc(prefix+"trap_bytes_total", "bytes hit by the trap", trapLabels, nil)
+
+	groupLabels := []string{"device", "group", "policer"}
+	trapGroupPacketsDesc = prometheus.NewDesc(prefix+"trap_group_packets_total", "packets hit by the traps of the group", groupLabels, nil)
+	trapGroupBytesDesc = prometheus.NewDesc(prefix+"trap_group_bytes_total", "bytes hit by the traps of the group", groupLabels, nil)
+
+	policerLabels := []string{"device", "policer"}
+	trapPolicerDroppedDesc = prometheus.NewDesc(prefix+"trap_policer_dropped_packets_total", "trapped packets dropped by the policer", policerLabels, nil)
+	trapPolicerRateDesc = prometheus.NewDesc(prefix+"trap_policer_rate_packets_per_second", "policer rate", policerLabels, nil)
+	trapPolicerBurstDesc = prometheus.NewDesc(prefix+"trap_policer_burst_packets", "policer burst size", policerLabels, nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- trapPacketsDesc
+	ch <- trapBytesDesc
+	ch <- trapGroupPacketsDesc
+	ch <- trapGroupBytesDesc
+	ch <- trapPolicerDroppedDesc
+	ch <- trapPolicerRateDesc
+	ch <- trapPolicerBurstDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	traps, err := GetTraps()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink traps")
+		return
+	}
+	for _, trap := range traps {
+		labels := []string{trap.Device.String(), trap.Name, trap.Group, TrapActionString(trap.Action), TrapTypeString(trap.Type)}
+		metrics <- prometheus.MustNewConstMetric(trapPacketsDesc, prometheus.CounterValue, float64(trap.Stats.RxPackets), labels...)
+		metrics <- prometheus.MustNewConstMetric(trapBytesDesc, prometheus.CounterValue, float64(trap.Stats.RxBytes), labels...)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	groups, err := GetTrapGroups()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink trap groups")
+		return
+	}
+	for _, group := range groups {
+		labels := []string{group.Device.String(), group.Name, formatPolicerID(group.PolicerID)}
+		metrics <- prometheus.MustNewConstMetric(trapGroupPacketsDesc, prometheus.CounterValue, float64(group.Stats.RxPackets), labels...)
+		metrics <- prometheus.MustNewConstMetric(trapGroupBytesDesc, prometheus.CounterValue, float64(group.Stats.RxBytes), labels...)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	policers, err := GetTrapPolicers()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink trap policers")
+		return
+	}
+	for _, policer := range policers {
+		labels := []string{policer.Device.String(), formatPolicerID(policer.ID)}
+		metrics <- prometheus.MustNewConstMetric(trapPolicerDroppedDesc, prometheus.CounterValue, float64(policer.Stats.RxDropped), labels...)
+		metrics <- prometheus.MustNewConstMetric(trapPolicerRateDesc, prometheus.GaugeValue, float64(policer.Rate), labels...)
+		metrics <- prometheus.MustNewConstMetric(trapPolicerBurstDesc, prometheus.GaugeValue, float64(policer.Burst), labels...)
+	}
+}
+
+// Name returns the string "DevlinkCollector"
+func (*Collector) Name() string {
+	return "DevlinkCollector"
+}
+
+// formatPolicerID returns the policer's id, trap groups without policer have id 0
+func formatPolicerID(id uint32) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,81 @@
+package devlink
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// healthReporterStateHealthy is DEVLINK_HEALTH_REPORTER_STATE_HEALTHY, which
+// golang.org/x/sys does not define
+const healthReporterStateHealthy = 0
+
+// HealthReporter stores the state of a health reporter of a devlink device or port
+type HealthReporter struct {
+	Device       Device
+	PortIndex    *uint32
+	Name         string
+	Healthy      bool
+	ErrorCount   uint64
+	RecoverCount uint64
+	AutoRecover  bool
+}
+
+// GetHealthReporters returns the health reporters of all devlink devices and ports
+func GetHealthReporters() ([]*HealthReporter, error) {
+	objects, err := dump(unix.DEVLINK_CMD_HEALTH_REPORTER_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseHealthReporters(objects)
+}
+
+// parseHealthReporters parses the health reporters of a dump reply
+func parseHealthReporters(objects [][]syscall.NetlinkRouteAttr) ([]*HealthReporter, error) {
+	reporters := make([]*HealthReporter, 0, len(objects))
+	for _, attrs := range objects {
+		reporter := &HealthReporter{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				reporter.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				reporter.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_PORT_INDEX:
+				portIndex := parseUint32(attr.Value)
+				reporter.PortIndex = &portIndex
+			case unix.DEVLINK_ATTR_HEALTH_REPORTER:
+				err := reporter.parseNested(attr.Value)
+				if err != nil {
+					return nil, errors.Wrap(err, "Could not parse devlink health reporter")
+				}
+			}
+		}
+		reporters = append(reporters, reporter)
+	}
+	return reporters, nil
+}
+
+func (r *HealthReporter) parseNested(b []byte) error {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return err
+	}
+	for _, attr := range attrs {
+		switch attrType(attr) {
+		case unix.DEVLINK_ATTR_HEALTH_REPORTER_NAME:
+			r.Name = parseString(attr.Value)
+		case unix.DEVLINK_ATTR_HEALTH_REPORTER_STATE:
+			r.Healthy = parseUint8(attr.Value) == healthReporterStateHealthy
+		case unix.DEVLINK_ATTR_HEALTH_REPORTER_ERR_COUNT:
+			r.ErrorCount = parseUint64(attr.Value)
+		case unix.DEVLINK_ATTR_HEALTH_REPORTER_RECOVER_COUNT:
+			r.RecoverCount = parseUint64(attr.Value)
+		case unix.DEVLINK_ATTR_HEALTH_REPORTER_AUTO_RECOVER:
+			r.AutoRecover = parseUint8(attr.Value) != 0
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package devlink
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const healthPrefix = "devlink_health_reporter_"
+
+var (
+	healthReporterHealthyDesc     *prometheus.Desc
+	healthReporterErrorsDesc      *prometheus.Desc
+	healthReporterRecoveriesDesc  *prometheus.Desc
+	healthReporterAutoRecoverDesc *prometheus.Desc
+)
+
+// HealthCollector collects the state of devlink health reporters
+type HealthCollector struct {
+}
+
+// NewHealthCollector returns a new HealthCollector instance
+func NewHealthCollector() *HealthCollector {
+	return &HealthCollector{}
+}
+
+func init() {
+	collector.Register("devlink_health", false, nil, func(collector.Config) (collector.Collector, error) {
+		return NewHealthCollector(), nil
+	})
+
+	labels := []string{"device", "interface", "reporter"}
+	healthReporterHealthyDesc = prometheus.NewDesc(healthPrefix+"healthy_bool", "reporter is in healthy state", labels, nil)
+	healthReporterErrorsDesc = prometheus.NewDesc(healthPrefix+"errors_total", "errors reported", labels, nil)
+	healthReporterRecoveriesDesc = prometheus.NewDesc(healthPrefix+"recoveries_total", "recoveries performed", labels, nil)
+	healthReporterAutoRecoverDesc = prometheus.NewDesc(healthPrefix+"auto_recover_bool", "errors are recovered automatically", labels, nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*HealthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- healthReporterHealthyDesc
+	ch <- healthReporterErrorsDesc
+	ch <- healthReporterRecoveriesDesc
+	ch <- healthReporterAutoRecoverDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *HealthCollector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	reporters, err := GetHealthReporters()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink health reporters")
+		return
+	}
+
+	var portNames map[Device]map[uint32]string
+	for _, reporter := range reporters {
+		iface := ""
+		if reporter.PortIndex != nil {
+			if portNames == nil {
+				portNames, err = getPortNames()
+				if err != nil {
+					errorChan <- err
+					return
+				}
+			}
+			iface = portName(portNames, reporter.Device, *reporter.PortIndex)
+		}
+
+		labels := []string{reporter.Device.String(), iface, reporter.Name}
+		metrics <- prometheus.MustNewConstMetric(healthReporterHealthyDesc, prometheus.GaugeValue, util.BoolToFloat64(reporter.Healthy), labels...)
+		metrics <- prometheus.MustNewConstMetric(healthReporterErrorsDesc, prometheus.CounterValue, float64(reporter.ErrorCount), labels...)
+		metrics <- prometheus.MustNewConstMetric(healthReporterRecoveriesDesc, prometheus.CounterValue, float64(reporter.RecoverCount), labels...)
+		metrics <- prometheus.MustNewConstMetric(healthReporterAutoRecoverDesc, prometheus.GaugeValue, util.BoolToFloat64(reporter.AutoRecover), labels...)
+	}
+}
+
+// Name returns the string "DevlinkHealthCollector"
+func (*HealthCollector) Name() string {
+	return "DevlinkHealthCollector"
+}
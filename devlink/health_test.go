@@ -0,0 +1,74 @@
+package devlink
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func healthReporterAttr(name string, state uint8, errors uint64, recovers uint64, autoRecover bool) *nl.RtAttr {
+	attr := nl.NewRtAttr(unix.DEVLINK_ATTR_HEALTH_REPORTER|unix.NLA_F_NESTED, nil)
+	attr.AddRtAttr(unix.DEVLINK_ATTR_HEALTH_REPORTER_NAME, nl.ZeroTerminated(name))
+	attr.AddRtAttr(unix.DEVLINK_ATTR_HEALTH_REPORTER_STATE, nl.Uint8Attr(state))
+	attr.AddRtAttr(unix.DEVLINK_ATTR_HEALTH_REPORTER_ERR_COUNT, nl.Uint64Attr(errors))
+	attr.AddRtAttr(unix.DEVLINK_ATTR_HEALTH_REPORTER_RECOVER_COUNT, nl.Uint64Attr(recovers))
+	attr.AddRtAttr(unix.DEVLINK_ATTR_HEALTH_REPORTER_GRACEFUL_PERIOD, nl.Uint64Attr(500))
+	autoRecoverValue := uint8(0)
+	if autoRecover {
+		autoRecoverValue = 1
+	}
+	attr.AddRtAttr(unix.DEVLINK_ATTR_HEALTH_REPORTER_AUTO_RECOVER, nl.Uint8Attr(autoRecoverValue))
+	return attr
+}
+
+func TestParseHealthReporters(t *testing.T) {
+	// the device reporters of netdevsim, dummy after an error was triggered,
+	// and a port reporter
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_HEALTH_REPORTER_GET, append(deviceAttrs("netdevsim", "netdevsim10"),
+			healthReporterAttr("empty", 0, 0, 0, true),
+		)...),
+		reply(unix.DEVLINK_CMD_HEALTH_REPORTER_GET, append(deviceAttrs("netdevsim", "netdevsim10"),
+			healthReporterAttr("dummy", 1, 2, 1, false),
+		)...),
+		reply(unix.DEVLINK_CMD_HEALTH_REPORTER_GET, append(deviceAttrs("pci", "0000:01:00.0"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_PORT_INDEX, nl.Uint32Attr(7)),
+			healthReporterAttr("tx", 0, 0, 0, true),
+		)...),
+	)
+
+	reporters, err := parseHealthReporters(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	portIndex := uint32(7)
+	netdevsim := Device{Bus: "netdevsim", Name: "netdevsim10"}
+	expected := []*HealthReporter{
+		{Device: netdevsim, Name: "empty", Healthy: true, AutoRecover: true},
+		{Device: netdevsim, Name: "dummy", ErrorCount: 2, RecoverCount: 1},
+		{Device: Device{Bus: "pci", Name: "0000:01:00.0"}, PortIndex: &portIndex, Name: "tx", Healthy: true, AutoRecover: true},
+	}
+	if !reflect.DeepEqual(reporters, expected) {
+		t.Errorf("got health reporters %+v, want %+v", reporters, expected)
+	}
+}
+
+func TestNetdevsimHealthReporters(t *testing.T) {
+	requireNetdevsim(t)
+
+	reporters, err := GetHealthReporters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, reporter := range reporters {
+		if reporter.Device.Bus == "netdevsim" && reporter.Name == "dummy" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("netdevsim health reporter dummy not found in %+v", reporters)
+	}
+}
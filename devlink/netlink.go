@@ -0,0 +1,156 @@
+package devlink
+
+import (
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// dump sends a devlink dump request for cmd and returns the attributes of
+// every object the kernel replied with
+func dump(cmd uint8) ([][]syscall.NetlinkRouteAttr, error) {
+	return execute(cmd, unix.NLM_F_DUMP)
+}
+
+// execute sends a devlink request for cmd carrying attrs and returns the
+// attributes of every object the kernel replied with
+func execute(cmd uint8, flags int, attrs ...*nl.RtAttr) ([][]syscall.NetlinkRouteAttr, error) {
+	family, err := netlink.GenlFamilyGet(nl.GENL_DEVLINK_NAME)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not resolve devlink generic netlink family")
+	}
+
+	req := nl.NewNetlinkRequest(int(family.ID), unix.NLM_F_REQUEST|unix.NLM_F_ACK|flags)
+	req.AddData(&nl.Genlmsg{
+		Command: cmd,
+		Version: nl.GENL_DEVLINK_VERSION,
+	})
+	for _, attr := range attrs {
+		req.AddData(attr)
+	}
+	msgs, err := req.Execute(unix.NETLINK_GENERIC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return parseMessages(msgs)
+}
+
+// parseMessages returns the attributes of the generic netlink messages msgs,
+// the payloads of the kernel's replies
+func parseMessages(msgs [][]byte) ([][]syscall.NetlinkRouteAttr, error) {
+	res := make([][]syscall.NetlinkRouteAttr, 0, len(msgs))
+	for _, msg := range msgs {
+		if len(msg) < nl.SizeofGenlmsg {
+			return nil, errors.New("Short devlink message")
+		}
+		attrs, err := nl.ParseRouteAttr(msg[nl.SizeofGenlmsg:])
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not parse devlink message")
+		}
+		res = append(res, attrs)
+	}
+	return res, nil
+}
+
+// getPortNames returns the netdev names of all devlink ports, indexed by
+// device and port index
+func getPortNames() (map[Device]map[uint32]string, error) {
+	ports, err := netlink.DevLinkGetAllPortList()
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not dump devlink ports")
+	}
+
+	names := map[Device]map[uint32]string{}
+	for _, port := range ports {
+		device := Device{Bus: port.BusName, Name: port.DeviceName}
+		if names[device] == nil {
+			names[device] = map[uint32]string{}
+		}
+		names[device][port.PortIndex] = port.NetdeviceName
+	}
+	return names, nil
+}
+
+// Device identifies a devlink device, e.g. pci/0000:01:00.0
+type Device struct {
+	Bus  string
+	Name string
+}
+
+// String returns the device the way devlink prints it
+func (d Device) String() string {
+	return d.Bus + "/" + d.Name
+}
+
+func (d Device) attrs() []*nl.RtAttr {
+	return []*nl.RtAttr{
+		nl.NewRtAttr(unix.DEVLINK_ATTR_BUS_NAME, nl.ZeroTerminated(d.Bus)),
+		nl.NewRtAttr(unix.DEVLINK_ATTR_DEV_NAME, nl.ZeroTerminated(d.Name)),
+	}
+}
+
+// Stats stores the counters devlink reports for traps, trap groups and policers
+type Stats struct {
+	RxPackets uint64
+	RxBytes   uint64
+	RxDropped uint64
+}
+
+func parseStats(b []byte) (Stats, error) {
+	stats := Stats{}
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return stats, err
+	}
+	for _, attr := range attrs {
+		switch attrType(attr) {
+		case unix.DEVLINK_ATTR_STATS_RX_PACKETS:
+			stats.RxPackets = parseUint64(attr.Value)
+		case unix.DEVLINK_ATTR_STATS_RX_BYTES:
+			stats.RxBytes = parseUint64(attr.Value)
+		case unix.DEVLINK_ATTR_STATS_RX_DROPPED:
+			stats.RxDropped = parseUint64(attr.Value)
+		}
+	}
+	return stats, nil
+}
+
+func attrType(attr syscall.NetlinkRouteAttr) uint16 {
+	return attr.Attr.Type & nl.NLA_TYPE_MASK
+}
+
+func parseString(b []byte) string {
+	return strings.TrimRight(string(b), "\x00")
+}
+
+func parseUint8(b []byte) uint8 {
+	if len(b) < 1 {
+		return 0
+	}
+	return b[0]
+}
+
+func parseUint16(b []byte) uint16 {
+	if len(b) < 2 {
+		return 0
+	}
+	return nl.NativeEndian().Uint16(b)
+}
+
+func parseUint32(b []byte) uint32 {
+	if len(b) < 4 {
+		return 0
+	}
+	return nl.NativeEndian().Uint32(b)
+}
+
+func parseUint64(b []byte) uint64 {
+	if len(b) < 8 {
+		return 0
+	}
+	return nl.NativeEndian().Uint64(b)
+}
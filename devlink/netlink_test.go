@@ -0,0 +1,67 @@
+package devlink
+
+import (
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// reply serializes a devlink reply message the way the kernel sends it
+func reply(cmd uint8, attrs ...*nl.RtAttr) []byte {
+	b := (&nl.Genlmsg{Command: cmd, Version: nl.GENL_DEVLINK_VERSION}).Serialize()
+	for _, attr := range attrs {
+		b = append(b, attr.Serialize()...)
+	}
+	return b
+}
+
+// parseReplies parses reply messages the way the replies to a request are
+func parseReplies(t *testing.T, msgs ...[]byte) [][]syscall.NetlinkRouteAttr {
+	t.Helper()
+	objects, err := parseMessages(msgs)
+	if err != nil {
+		t.Fatalf("parsing messages failed: %v", err)
+	}
+	return objects
+}
+
+func deviceAttrs(bus string, name string) []*nl.RtAttr {
+	return []*nl.RtAttr{
+		nl.NewRtAttr(unix.DEVLINK_ATTR_BUS_NAME, nl.ZeroTerminated(bus)),
+		nl.NewRtAttr(unix.DEVLINK_ATTR_DEV_NAME, nl.ZeroTerminated(name)),
+	}
+}
+
+func stringAttr(attrType int, s string) *nl.RtAttr {
+	return nl.NewRtAttr(attrType, nl.ZeroTerminated(s))
+}
+
+func statsAttr(packets uint64, bytes uint64, dropped uint64) *nl.RtAttr {
+	attr := nl.NewRtAttr(unix.DEVLINK_ATTR_STATS|unix.NLA_F_NESTED, nil)
+	attr.AddRtAttr(unix.DEVLINK_ATTR_STATS_RX_PACKETS, nl.Uint64Attr(packets))
+	attr.AddRtAttr(unix.DEVLINK_ATTR_STATS_RX_BYTES, nl.Uint64Attr(bytes))
+	if dropped > 0 {
+		attr.AddRtAttr(unix.DEVLINK_ATTR_STATS_RX_DROPPED, nl.Uint64Attr(dropped))
+	}
+	return attr
+}
+
+func TestParseMessagesShort(t *testing.T) {
+	_, err := parseMessages([][]byte{{unix.DEVLINK_CMD_TRAP_NEW}})
+	if err == nil {
+		t.Error("parsing a message shorter than the generic netlink header succeeded")
+	}
+}
+
+// requireNetdevsim skips tests running against the kernel unless a netdevsim
+// device exists, e.g. after `echo "10 1" > /sys/bus/netdevsim/new_device`
+func requireNetdevsim(t *testing.T) {
+	t.Helper()
+	devices, _ := filepath.Glob("/sys/bus/netdevsim/devices/netdevsim*")
+	if len(devices) == 0 {
+		t.Skip("no netdevsim device")
+	}
+}
@@ -0,0 +1,266 @@
+package devlink
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// SharedBuffer stores a shared buffer of a devlink device
+type SharedBuffer struct {
+	Device Device
+	Index  uint32
+	Size   uint32
+}
+
+// SharedBufferPool stores a pool of a shared buffer
+type SharedBufferPool struct {
+	Device        Device
+	SbIndex       uint32
+	Index         uint16
+	Type          uint8
+	Size          uint32
+	ThresholdType uint8
+}
+
+// SharedBufferPortPool stores the threshold and occupancy of a port within a pool
+type SharedBufferPortPool struct {
+	Device    Device
+	PortIndex uint32
+	SbIndex   uint32
+	PoolIndex uint16
+	Threshold uint32
+	Occupancy *Occupancy
+}
+
+// SharedBufferTcBinding stores the pool a traffic class of a port is bound
+// to, its threshold and occupancy
+type SharedBufferTcBinding struct {
+	Device    Device
+	PortIndex uint32
+	SbIndex   uint32
+	TcIndex   uint16
+	PoolType  uint8
+	PoolIndex uint16
+	Threshold uint32
+	Occupancy *Occupancy
+}
+
+// Occupancy stores the current and maximum occupancy as of the last snapshot.
+// It is nil if the driver does not report occupancy.
+type Occupancy struct {
+	Current uint32
+	Max     uint32
+}
+
+// GetSharedBuffers returns the shared buffers of all devlink devices
+func GetSharedBuffers() ([]*SharedBuffer, error) {
+	objects, err := dump(unix.DEVLINK_CMD_SB_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseSharedBuffers(objects)
+}
+
+// parseSharedBuffers parses the shared buffers of a dump reply
+func parseSharedBuffers(objects [][]syscall.NetlinkRouteAttr) ([]*SharedBuffer, error) {
+	buffers := make([]*SharedBuffer, 0, len(objects))
+	for _, attrs := range objects {
+		buffer := &SharedBuffer{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				buffer.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				buffer.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_SB_INDEX:
+				buffer.Index = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_SB_SIZE:
+				buffer.Size = parseUint32(attr.Value)
+			}
+		}
+		buffers = append(buffers, buffer)
+	}
+	return buffers, nil
+}
+
+// GetSharedBufferPools returns the shared buffer pools of all devlink devices
+func GetSharedBufferPools() ([]*SharedBufferPool, error) {
+	objects, err := dump(unix.DEVLINK_CMD_SB_POOL_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseSharedBufferPools(objects)
+}
+
+// parseSharedBufferPools parses the shared buffer pools of a dump reply
+func parseSharedBufferPools(objects [][]syscall.NetlinkRouteAttr) ([]*SharedBufferPool, error) {
+	pools := make([]*SharedBufferPool, 0, len(objects))
+	for _, attrs := range objects {
+		pool := &SharedBufferPool{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				pool.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				pool.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_SB_INDEX:
+				pool.SbIndex = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_SB_POOL_INDEX:
+				pool.Index = parseUint16(attr.Value)
+			case unix.DEVLINK_ATTR_SB_POOL_TYPE:
+				pool.Type = parseUint8(attr.Value)
+			case unix.DEVLINK_ATTR_SB_POOL_SIZE:
+				pool.Size = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_SB_POOL_THRESHOLD_TYPE:
+				pool.ThresholdType = parseUint8(attr.Value)
+			}
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// GetSharedBufferPortPools returns the per port pool thresholds and
+// occupancy of all devlink devices
+func GetSharedBufferPortPools() ([]*SharedBufferPortPool, error) {
+	objects, err := dump(unix.DEVLINK_CMD_SB_PORT_POOL_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseSharedBufferPortPools(objects)
+}
+
+// parseSharedBufferPortPools parses the per port pools of a dump reply
+func parseSharedBufferPortPools(objects [][]syscall.NetlinkRouteAttr) ([]*SharedBufferPortPool, error) {
+	portPools := make([]*SharedBufferPortPool, 0, len(objects))
+	for _, attrs := range objects {
+		portPool := &SharedBufferPortPool{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				portPool.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				portPool.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_PORT_INDEX:
+				portPool.PortIndex = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_SB_INDEX:
+				portPool.SbIndex = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_SB_POOL_INDEX:
+				portPool.PoolIndex = parseUint16(attr.Value)
+			case unix.DEVLINK_ATTR_SB_THRESHOLD:
+				portPool.Threshold = parseUint32(attr.Value)
+			}
+		}
+		portPool.Occupancy = parseOccupancy(attrs)
+		portPools = append(portPools, portPool)
+	}
+	return portPools, nil
+}
+
+// GetSharedBufferTcBindings returns the per port and traffic class pool
+// bindings, thresholds and occupancy of all devlink devices
+func GetSharedBufferTcBindings() ([]*SharedBufferTcBinding, error) {
+	objects, err := dump(unix.DEVLINK_CMD_SB_TC_POOL_BIND_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseSharedBufferTcBindings(objects)
+}
+
+// parseSharedBufferTcBindings parses the traffic class bindings of a dump reply
+func parseSharedBufferTcBindings(objects [][]syscall.NetlinkRouteAttr) ([]*SharedBufferTcBinding, error) {
+	bindings := make([]*SharedBufferTcBinding, 0, len(objects))
+	for _, attrs := range objects {
+		binding := &SharedBufferTcBinding{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				binding.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				binding.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_PORT_INDEX:
+				binding.PortIndex = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_SB_INDEX:
+				binding.SbIndex = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_SB_TC_INDEX:
+				binding.TcIndex = parseUint16(attr.Value)
+			case unix.DEVLINK_ATTR_SB_POOL_TYPE:
+				binding.PoolType = parseUint8(attr.Value)
+			case unix.DEVLINK_ATTR_SB_POOL_INDEX:
+				binding.PoolIndex = parseUint16(attr.Value)
+			case unix.DEVLINK_ATTR_SB_THRESHOLD:
+				binding.Threshold = parseUint32(attr.Value)
+			}
+		}
+		binding.Occupancy = parseOccupancy(attrs)
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
+
+func parseOccupancy(attrs []syscall.NetlinkRouteAttr) *Occupancy {
+	var occupancy *Occupancy
+	for _, attr := range attrs {
+		switch attrType(attr) {
+		case unix.DEVLINK_ATTR_SB_OCC_CUR:
+			if occupancy == nil {
+				occupancy = &Occupancy{}
+			}
+			occupancy.Current = parseUint32(attr.Value)
+		case unix.DEVLINK_ATTR_SB_OCC_MAX:
+			if occupancy == nil {
+				occupancy = &Occupancy{}
+			}
+			occupancy.Max = parseUint32(attr.Value)
+		}
+	}
+	return occupancy
+}
+
+// SnapshotOccupancy makes the device copy the occupancy of the shared buffer,
+// the following port pool and traffic class dumps report this snapshot
+func SnapshotOccupancy(buffer *SharedBuffer) error {
+	_, err := execute(unix.DEVLINK_CMD_SB_OCC_SNAPSHOT, 0, sharedBufferAttrs(buffer)...)
+	if err != nil {
+		return errors.Wrapf(err, "Could not take occupancy snapshot of %s shared buffer %d", buffer.Device, buffer.Index)
+	}
+	return nil
+}
+
+// ClearOccupancyMax resets the maximum occupancy watermarks of the shared buffer
+func ClearOccupancyMax(buffer *SharedBuffer) error {
+	_, err := execute(unix.DEVLINK_CMD_SB_OCC_MAX_CLEAR, 0, sharedBufferAttrs(buffer)...)
+	if err != nil {
+		return errors.Wrapf(err, "Could not clear occupancy watermarks of %s shared buffer %d", buffer.Device, buffer.Index)
+	}
+	return nil
+}
+
+func sharedBufferAttrs(buffer *SharedBuffer) []*nl.RtAttr {
+	return append(buffer.Device.attrs(), nl.NewRtAttr(unix.DEVLINK_ATTR_SB_INDEX, nl.Uint32Attr(buffer.Index)))
+}
+
+// PoolTypeString returns the name devlink uses for a pool type
+func PoolTypeString(poolType uint8) string {
+	switch poolType {
+	case unix.DEVLINK_SB_POOL_TYPE_INGRESS:
+		return "ingress"
+	case unix.DEVLINK_SB_POOL_TYPE_EGRESS:
+		return "egress"
+	}
+	return "unknown"
+}
+
+// ThresholdTypeString returns the name devlink uses for a threshold type
+func ThresholdTypeString(thresholdType uint8) string {
+	switch thresholdType {
+	case unix.DEVLINK_SB_THRESHOLD_TYPE_STATIC:
+		return "static"
+	case unix.DEVLINK_SB_THRESHOLD_TYPE_DYNAMIC:
+		return "dynamic"
+	}
+	return "unknown"
+}
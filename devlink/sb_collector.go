@@ -0,0 +1,194 @@
+package devlink
+
+import (
+	"context"
+	"flag"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+)
+
+const sbPrefix = "devlink_sb_"
+
+var (
+	occupancy       = flag.Bool("collector.devlink_sb.occupancy", true, "Take an occupancy snapshot of the shared buffers on every collection")
+	clearWatermarks = flag.Bool("collector.devlink_sb.clear-watermarks", false, "Clear the maximum occupancy watermarks after every collection")
+
+	// occupancyLock serializes taking an occupancy snapshot and reading
+	// it, as the snapshot is global to the device
+	occupancyLock = &sync.Mutex{}
+
+	sbSizeDesc              *prometheus.Desc
+	sbPoolSizeDesc          *prometheus.Desc
+	sbPoolInfoDesc          *prometheus.Desc
+	sbPortPoolThresholdDesc *prometheus.Desc
+	sbPortPoolOccCurDesc    *prometheus.Desc
+	sbPortPoolOccMaxDesc    *prometheus.Desc
+	sbTcThresholdDesc       *prometheus.Desc
+	sbTcOccCurDesc          *prometheus.Desc
+	sbTcOccMaxDesc          *prometheus.Desc
+)
+
+// SharedBufferConfig configures the devlink_sb collector
+type SharedBufferConfig struct {
+	collector.Settings `yaml:",inline"`
+	Occupancy          bool `yaml:"occupancy"`
+	ClearWatermarks    bool `yaml:"clear_watermarks"`
+}
+
+// SharedBufferCollector collects the devlink shared buffer configuration and occupancy
+type SharedBufferCollector struct {
+	occupancy       bool
+	clearWatermarks bool
+}
+
+// NewSharedBufferCollector returns a new SharedBufferCollector instance
+func NewSharedBufferCollector(occupancy bool, clearWatermarks bool) *SharedBufferCollector {
+	return &SharedBufferCollector{
+		occupancy:       occupancy,
+		clearWatermarks: clearWatermarks,
+	}
+}
+
+func init() {
+	collector.Register("devlink_sb", false, func() collector.Config {
+		return &SharedBufferConfig{
+			Occupancy:       *occupancy,
+			ClearWatermarks: *clearWatermarks,
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		config := cfg.(*SharedBufferConfig)
+		return NewSharedBufferCollector(config.Occupancy, config.ClearWatermarks), nil
+	})
+
+	sbLabels := []string{"device", "sb"}
+	poolLabels := append(sbLabels, "pool", "type")
+	portPoolLabels := append(sbLabels, "pool", "interface")
+	tcLabels := append(sbLabels, "interface", "tc", "type", "pool")
+	sbSizeDesc = prometheus.NewDesc(sbPrefix+"size_bytes", "shared buffer size", sbLabels, nil)
+	sbPoolSizeDesc = prometheus.NewDesc(sbPrefix+"pool_size_bytes", "pool size", poolLabels, nil)
+	sbPoolInfoDesc = prometheus.NewDesc(sbPrefix+"pool_info", "pool threshold type", append(poolLabels, "threshold_type"), nil)
+	sbPortPoolThresholdDesc = prometheus.NewDesc(sbPrefix+"port_pool_threshold", "port pool threshold, in bytes for static and as alpha index for dynamic pools", portPoolLabels, nil)
+	sbPortPoolOccCurDesc = prometheus.NewDesc(sbPrefix+"port_pool_occupancy_bytes", "port pool occupancy as of the last snapshot", portPoolLabels, nil)
+	sbPortPoolOccMaxDesc = prometheus.NewDesc(sbPrefix+"port_pool_occupancy_max_bytes", "port pool maximum occupancy since the watermarks were cleared", portPoolLabels, nil)
+	sbTcThresholdDesc = prometheus.NewDesc(sbPrefix+"tc_threshold", "traffic class threshold, in bytes for static and as alpha index for dynamic pools", tcLabels, nil)
+	sbTcOccCurDesc = prometheus.NewDesc(sbPrefix+"tc_occupancy_bytes", "traffic class occupancy as of the last snapshot", tcLabels, nil)
+	sbTcOccMaxDesc = prometheus.NewDesc(sbPrefix+"tc_occupancy_max_bytes", "traffic class maximum occupancy since the watermarks were cleared", tcLabels, nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*SharedBufferCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sbSizeDesc
+	ch <- sbPoolSizeDesc
+	ch <- sbPoolInfoDesc
+	ch <- sbPortPoolThresholdDesc
+	ch <- sbPortPoolOccCurDesc
+	ch <- sbPortPoolOccMaxDesc
+	ch <- sbTcThresholdDesc
+	ch <- sbTcOccCurDesc
+	ch <- sbTcOccMaxDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *SharedBufferCollector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	buffers, err := GetSharedBuffers()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink shared buffers")
+		return
+	}
+	for _, buffer := range buffers {
+		metrics <- prometheus.MustNewConstMetric(sbSizeDesc, prometheus.GaugeValue, float64(buffer.Size), buffer.Device.String(), formatIndex(buffer.Index))
+	}
+
+	pools, err := GetSharedBufferPools()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink shared buffer pools")
+		return
+	}
+	for _, pool := range pools {
+		labels := []string{pool.Device.String(), formatIndex(pool.SbIndex), formatIndex(uint32(pool.Index)), PoolTypeString(pool.Type)}
+		metrics <- prometheus.MustNewConstMetric(sbPoolSizeDesc, prometheus.GaugeValue, float64(pool.Size), labels...)
+		infoLabels := append(labels, ThresholdTypeString(pool.ThresholdType))
+		metrics <- prometheus.MustNewConstMetric(sbPoolInfoDesc, prometheus.GaugeValue, 1.0, infoLabels...)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	portNames, err := getPortNames()
+	if err != nil {
+		errorChan <- err
+		return
+	}
+
+	if c.occupancy {
+		occupancyLock.Lock()
+		defer occupancyLock.Unlock()
+		for _, buffer := range buffers {
+			err = SnapshotOccupancy(buffer)
+			if err != nil {
+				errorChan <- err
+				return
+			}
+		}
+	}
+
+	portPools, err := GetSharedBufferPortPools()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink shared buffer port pools")
+		return
+	}
+	for _, portPool := range portPools {
+		labels := []string{portPool.Device.String(), formatIndex(portPool.SbIndex), formatIndex(uint32(portPool.PoolIndex)), portName(portNames, portPool.Device, portPool.PortIndex)}
+		metrics <- prometheus.MustNewConstMetric(sbPortPoolThresholdDesc, prometheus.GaugeValue, float64(portPool.Threshold), labels...)
+		if c.occupancy && portPool.Occupancy != nil {
+			metrics <- prometheus.MustNewConstMetric(sbPortPoolOccCurDesc, prometheus.GaugeValue, float64(portPool.Occupancy.Current), labels...)
+			metrics <- prometheus.MustNewConstMetric(sbPortPoolOccMaxDesc, prometheus.GaugeValue, float64(portPool.Occupancy.Max), labels...)
+		}
+	}
+
+	bindings, err := GetSharedBufferTcBindings()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve devlink shared buffer traffic class bindings")
+		return
+	}
+	for _, binding := range bindings {
+		labels := []string{binding.Device.String(), formatIndex(binding.SbIndex), portName(portNames, binding.Device, binding.PortIndex), formatIndex(uint32(binding.TcIndex)), PoolTypeString(binding.PoolType), formatIndex(uint32(binding.PoolIndex))}
+		metrics <- prometheus.MustNewConstMetric(sbTcThresholdDesc, prometheus.GaugeValue, float64(binding.Threshold), labels...)
+		if c.occupancy && binding.Occupancy != nil {
+			metrics <- prometheus.MustNewConstMetric(sbTcOccCurDesc, prometheus.GaugeValue, float64(binding.Occupancy.Current), labels...)
+			metrics <- prometheus.MustNewConstMetric(sbTcOccMaxDesc, prometheus.GaugeValue, float64(binding.Occupancy.Max), labels...)
+		}
+	}
+
+	if c.occupancy && c.clearWatermarks {
+		for _, buffer := range buffers {
+			err = ClearOccupancyMax(buffer)
+			if err != nil {
+				errorChan <- err
+				return
+			}
+		}
+	}
+}
+
+// Name returns the string "DevlinkSharedBufferCollector"
+func (*SharedBufferCollector) Name() string {
+	return "DevlinkSharedBufferCollector"
+}
+
+func formatIndex(index uint32) string {
+	return strconv.FormatUint(uint64(index), 10)
+}
+
+// portName returns the netdev name of a devlink port, or its index if it has none
+func portName(portNames map[Device]map[uint32]string, device Device, portIndex uint32) string {
+	name := portNames[device][portIndex]
+	if name == "" {
+		return formatIndex(portIndex)
+	}
+	return name
+}
@@ -0,0 +1,128 @@
+package devlink
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// netdevsim has no shared buffers, the replies are the ones of a Spectrum ASIC
+
+func TestParseSharedBuffers(t *testing.T) {
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_SB_NEW, append(deviceAttrs("pci", "0000:01:00.0"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_INDEX, nl.Uint32Attr(0)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_SIZE, nl.Uint32Attr(14000000)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_INGRESS_POOL_COUNT, nl.Uint16Attr(4)),
+		)...),
+	)
+
+	buffers, err := parseSharedBuffers(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*SharedBuffer{
+		{Device: Device{Bus: "pci", Name: "0000:01:00.0"}, Index: 0, Size: 14000000},
+	}
+	if !reflect.DeepEqual(buffers, expected) {
+		t.Errorf("got shared buffers %+v, want %+v", buffers, expected)
+	}
+}
+
+func TestParseSharedBufferPools(t *testing.T) {
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_SB_POOL_NEW, append(deviceAttrs("pci", "0000:01:00.0"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_INDEX, nl.Uint32Attr(0)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_POOL_INDEX, nl.Uint16Attr(4)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_POOL_TYPE, nl.Uint8Attr(unix.DEVLINK_SB_POOL_TYPE_EGRESS)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_POOL_SIZE, nl.Uint32Attr(13768608)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_POOL_THRESHOLD_TYPE, nl.Uint8Attr(unix.DEVLINK_SB_THRESHOLD_TYPE_DYNAMIC)),
+		)...),
+	)
+
+	pools, err := parseSharedBufferPools(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*SharedBufferPool{
+		{
+			Device:        Device{Bus: "pci", Name: "0000:01:00.0"},
+			Index:         4,
+			Type:          unix.DEVLINK_SB_POOL_TYPE_EGRESS,
+			Size:          13768608,
+			ThresholdType: unix.DEVLINK_SB_THRESHOLD_TYPE_DYNAMIC,
+		},
+	}
+	if !reflect.DeepEqual(pools, expected) {
+		t.Errorf("got pools %+v, want %+v", pools, expected)
+	}
+	if PoolTypeString(pools[0].Type) != "egress" || ThresholdTypeString(pools[0].ThresholdType) != "dynamic" {
+		t.Errorf("got type %s and threshold type %s, want egress and dynamic", PoolTypeString(pools[0].Type), ThresholdTypeString(pools[0].ThresholdType))
+	}
+}
+
+func TestParseSharedBufferPortPools(t *testing.T) {
+	attrs := append(deviceAttrs("pci", "0000:01:00.0"),
+		nl.NewRtAttr(unix.DEVLINK_ATTR_PORT_INDEX, nl.Uint32Attr(1)),
+		nl.NewRtAttr(unix.DEVLINK_ATTR_SB_INDEX, nl.Uint32Attr(0)),
+		nl.NewRtAttr(unix.DEVLINK_ATTR_SB_POOL_INDEX, nl.Uint16Attr(0)),
+		nl.NewRtAttr(unix.DEVLINK_ATTR_SB_THRESHOLD, nl.Uint32Attr(10)),
+	)
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_SB_PORT_POOL_NEW, append(attrs,
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_OCC_CUR, nl.Uint32Attr(1536)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_OCC_MAX, nl.Uint32Attr(30720)),
+		)...),
+		// drivers without occupancy support omit the occupancy attributes
+		reply(unix.DEVLINK_CMD_SB_PORT_POOL_NEW, attrs...),
+	)
+
+	portPools, err := parseSharedBufferPortPools(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := Device{Bus: "pci", Name: "0000:01:00.0"}
+	expected := []*SharedBufferPortPool{
+		{Device: device, PortIndex: 1, Threshold: 10, Occupancy: &Occupancy{Current: 1536, Max: 30720}},
+		{Device: device, PortIndex: 1, Threshold: 10},
+	}
+	if !reflect.DeepEqual(portPools, expected) {
+		t.Errorf("got port pools %+v, want %+v", portPools, expected)
+	}
+}
+
+func TestParseSharedBufferTcBindings(t *testing.T) {
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_SB_TC_POOL_BIND_NEW, append(deviceAttrs("pci", "0000:01:00.0"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_PORT_INDEX, nl.Uint32Attr(3)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_INDEX, nl.Uint32Attr(0)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_TC_INDEX, nl.Uint16Attr(3)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_POOL_TYPE, nl.Uint8Attr(unix.DEVLINK_SB_POOL_TYPE_EGRESS)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_POOL_INDEX, nl.Uint16Attr(4)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_THRESHOLD, nl.Uint32Attr(17)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_OCC_CUR, nl.Uint32Attr(0)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_SB_OCC_MAX, nl.Uint32Attr(4608)),
+		)...),
+	)
+
+	bindings, err := parseSharedBufferTcBindings(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*SharedBufferTcBinding{
+		{
+			Device:    Device{Bus: "pci", Name: "0000:01:00.0"},
+			PortIndex: 3,
+			TcIndex:   3,
+			PoolType:  unix.DEVLINK_SB_POOL_TYPE_EGRESS,
+			PoolIndex: 4,
+			Threshold: 17,
+			Occupancy: &Occupancy{Current: 0, Max: 4608},
+		},
+	}
+	if !reflect.DeepEqual(bindings, expected) {
+		t.Errorf("got traffic class bindings %+v, want %+v", bindings, expected)
+	}
+}
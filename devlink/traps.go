@@ -0,0 +1,181 @@
+package devlink
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// Trap stores a packet trap of a devlink device, e.g. a drop reason of the ASIC
+type Trap struct {
+	Device  Device
+	Name    string
+	Group   string
+	Type    uint8
+	Action  uint8
+	Generic bool
+	Stats   Stats
+}
+
+// TrapGroup stores a group of traps sharing a policer
+type TrapGroup struct {
+	Device    Device
+	Name      string
+	PolicerID uint32
+	Stats     Stats
+}
+
+// TrapPolicer stores a policer limiting the rate of trapped packets sent to the CPU
+type TrapPolicer struct {
+	Device Device
+	ID     uint32
+	Rate   uint64
+	Burst  uint64
+	Stats  Stats
+}
+
+// GetTraps returns the traps of all devlink devices
+func GetTraps() ([]*Trap, error) {
+	objects, err := dump(unix.DEVLINK_CMD_TRAP_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseTraps(objects)
+}
+
+// parseTraps parses the traps of a dump reply
+func parseTraps(objects [][]syscall.NetlinkRouteAttr) ([]*Trap, error) {
+	traps := make([]*Trap, 0, len(objects))
+	for _, attrs := range objects {
+		trap := &Trap{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				trap.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				trap.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_NAME:
+				trap.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_GROUP_NAME:
+				trap.Group = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_TYPE:
+				trap.Type = parseUint8(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_ACTION:
+				trap.Action = parseUint8(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_GENERIC:
+				trap.Generic = true
+			case unix.DEVLINK_ATTR_STATS:
+				stats, err := parseStats(attr.Value)
+				if err != nil {
+					return nil, errors.Wrap(err, "Could not parse devlink trap stats")
+				}
+				trap.Stats = stats
+			}
+		}
+		traps = append(traps, trap)
+	}
+	return traps, nil
+}
+
+// GetTrapGroups returns the trap groups of all devlink devices
+func GetTrapGroups() ([]*TrapGroup, error) {
+	objects, err := dump(unix.DEVLINK_CMD_TRAP_GROUP_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseTrapGroups(objects)
+}
+
+// parseTrapGroups parses the trap groups of a dump reply
+func parseTrapGroups(objects [][]syscall.NetlinkRouteAttr) ([]*TrapGroup, error) {
+	groups := make([]*TrapGroup, 0, len(objects))
+	for _, attrs := range objects {
+		group := &TrapGroup{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				group.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				group.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_GROUP_NAME:
+				group.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_POLICER_ID:
+				group.PolicerID = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_STATS:
+				stats, err := parseStats(attr.Value)
+				if err != nil {
+					return nil, errors.Wrap(err, "Could not parse devlink trap group stats")
+				}
+				group.Stats = stats
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// GetTrapPolicers returns the trap policers of all devlink devices
+func GetTrapPolicers() ([]*TrapPolicer, error) {
+	objects, err := dump(unix.DEVLINK_CMD_TRAP_POLICER_GET)
+	if err != nil {
+		return nil, err
+	}
+	return parseTrapPolicers(objects)
+}
+
+// parseTrapPolicers parses the trap policers of a dump reply
+func parseTrapPolicers(objects [][]syscall.NetlinkRouteAttr) ([]*TrapPolicer, error) {
+	policers := make([]*TrapPolicer, 0, len(objects))
+	for _, attrs := range objects {
+		policer := &TrapPolicer{}
+		for _, attr := range attrs {
+			switch attrType(attr) {
+			case unix.DEVLINK_ATTR_BUS_NAME:
+				policer.Device.Bus = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_DEV_NAME:
+				policer.Device.Name = parseString(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_POLICER_ID:
+				policer.ID = parseUint32(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_POLICER_RATE:
+				policer.Rate = parseUint64(attr.Value)
+			case unix.DEVLINK_ATTR_TRAP_POLICER_BURST:
+				policer.Burst = parseUint64(attr.Value)
+			case unix.DEVLINK_ATTR_STATS:
+				stats, err := parseStats(attr.Value)
+				if err != nil {
+					return nil, errors.Wrap(err, "Could not parse devlink trap policer stats")
+				}
+				policer.Stats = stats
+			}
+		}
+		policers = append(policers, policer)
+	}
+	return policers, nil
+}
+
+// TrapActionString returns the name devlink uses for a trap action
+func TrapActionString(action uint8) string {
+	switch action {
+	case unix.DEVLINK_TRAP_ACTION_DROP:
+		return "drop"
+	case unix.DEVLINK_TRAP_ACTION_TRAP:
+		return "trap"
+	case unix.DEVLINK_TRAP_ACTION_MIRROR:
+		return "mirror"
+	}
+	return "unknown"
+}
+
+// TrapTypeString returns the name devlink uses for a trap type
+func TrapTypeString(trapType uint8) string {
+	switch trapType {
+	case unix.DEVLINK_TRAP_TYPE_DROP:
+		return "drop"
+	case unix.DEVLINK_TRAP_TYPE_EXCEPTION:
+		return "exception"
+	case unix.DEVLINK_TRAP_TYPE_CONTROL:
+		return "control"
+	}
+	return "unknown"
+}
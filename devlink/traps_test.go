@@ -0,0 +1,159 @@
+package devlink
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseTraps(t *testing.T) {
+	// a generic and a driver specific trap as reported by netdevsim
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_TRAP_NEW, append(deviceAttrs("netdevsim", "netdevsim10"),
+			stringAttr(unix.DEVLINK_ATTR_TRAP_NAME, "source_mac_is_multicast"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_GENERIC, nil),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_ACTION, nl.Uint8Attr(unix.DEVLINK_TRAP_ACTION_DROP)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_TYPE, nl.Uint8Attr(unix.DEVLINK_TRAP_TYPE_DROP)),
+			stringAttr(unix.DEVLINK_ATTR_TRAP_GROUP_NAME, "l2_drops"),
+			statsAttr(21, 1344, 0),
+		)...),
+		reply(unix.DEVLINK_CMD_TRAP_NEW, append(deviceAttrs("netdevsim", "netdevsim10"),
+			stringAttr(unix.DEVLINK_ATTR_TRAP_NAME, "fid_miss"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_ACTION, nl.Uint8Attr(unix.DEVLINK_TRAP_ACTION_TRAP)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_TYPE, nl.Uint8Attr(unix.DEVLINK_TRAP_TYPE_EXCEPTION)),
+			stringAttr(unix.DEVLINK_ATTR_TRAP_GROUP_NAME, "l2_drops"),
+			statsAttr(3, 192, 1),
+		)...),
+	)
+
+	traps, err := parseTraps(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := Device{Bus: "netdevsim", Name: "netdevsim10"}
+	expected := []*Trap{
+		{
+			Device:  device,
+			Name:    "source_mac_is_multicast",
+			Group:   "l2_drops",
+			Type:    unix.DEVLINK_TRAP_TYPE_DROP,
+			Action:  unix.DEVLINK_TRAP_ACTION_DROP,
+			Generic: true,
+			Stats:   Stats{RxPackets: 21, RxBytes: 1344},
+		},
+		{
+			Device: device,
+			Name:   "fid_miss",
+			Group:  "l2_drops",
+			Type:   unix.DEVLINK_TRAP_TYPE_EXCEPTION,
+			Action: unix.DEVLINK_TRAP_ACTION_TRAP,
+			Stats:  Stats{RxPackets: 3, RxBytes: 192, RxDropped: 1},
+		},
+	}
+	if !reflect.DeepEqual(traps, expected) {
+		t.Errorf("got traps %+v, want %+v", traps, expected)
+	}
+	if TrapTypeString(traps[1].Type) != "exception" || TrapActionString(traps[1].Action) != "trap" {
+		t.Errorf("got type %s and action %s, want exception and trap", TrapTypeString(traps[1].Type), TrapActionString(traps[1].Action))
+	}
+}
+
+func TestParseTrapGroups(t *testing.T) {
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_TRAP_GROUP_NEW, append(deviceAttrs("netdevsim", "netdevsim10"),
+			stringAttr(unix.DEVLINK_ATTR_TRAP_GROUP_NAME, "buffer_drops"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_GENERIC, nil),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_POLICER_ID, nl.Uint32Attr(2)),
+			statsAttr(7, 448, 0),
+		)...),
+	)
+
+	groups, err := parseTrapGroups(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*TrapGroup{
+		{
+			Device:    Device{Bus: "netdevsim", Name: "netdevsim10"},
+			Name:      "buffer_drops",
+			PolicerID: 2,
+			Stats:     Stats{RxPackets: 7, RxBytes: 448},
+		},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("got trap groups %+v, want %+v", groups, expected)
+	}
+}
+
+func TestParseTrapPolicers(t *testing.T) {
+	// policer statistics only carry the packets dropped by the policer
+	stats := nl.NewRtAttr(unix.DEVLINK_ATTR_STATS|unix.NLA_F_NESTED, nil)
+	stats.AddRtAttr(unix.DEVLINK_ATTR_STATS_RX_DROPPED, nl.Uint64Attr(9))
+	objects := parseReplies(t,
+		reply(unix.DEVLINK_CMD_TRAP_POLICER_NEW, append(deviceAttrs("netdevsim", "netdevsim10"),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_POLICER_ID, nl.Uint32Attr(1)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_POLICER_RATE, nl.Uint64Attr(1000)),
+			nl.NewRtAttr(unix.DEVLINK_ATTR_TRAP_POLICER_BURST, nl.Uint64Attr(128)),
+			stats,
+		)...),
+	)
+
+	policers, err := parseTrapPolicers(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*TrapPolicer{
+		{
+			Device: Device{Bus: "netdevsim", Name: "netdevsim10"},
+			ID:     1,
+			Rate:   1000,
+			Burst:  128,
+			Stats:  Stats{RxDropped: 9},
+		},
+	}
+	if !reflect.DeepEqual(policers, expected) {
+		t.Errorf("got trap policers %+v, want %+v", policers, expected)
+	}
+}
+
+func TestParseTrapsInvalidStats(t *testing.T) {
+	// the nested attribute claims to be longer than the statistics it is in
+	stats := nl.NewRtAttr(unix.DEVLINK_ATTR_STATS|unix.NLA_F_NESTED, []byte{16, 0, unix.DEVLINK_ATTR_STATS_RX_PACKETS, 0, 0, 0, 0, 0})
+	objects := parseReplies(t, reply(unix.DEVLINK_CMD_TRAP_NEW, append(deviceAttrs("netdevsim", "netdevsim10"), stats)...))
+	_, err := parseTraps(objects)
+	if err == nil {
+		t.Error("parsing truncated trap statistics succeeded")
+	}
+}
+
+func TestNetdevsimTraps(t *testing.T) {
+	requireNetdevsim(t)
+
+	traps, err := GetTraps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, trap := range traps {
+		if trap.Device.Bus == "netdevsim" && trap.Name == "source_mac_is_multicast" {
+			found = trap.Generic && trap.Group == "l2_drops" && trap.Type == unix.DEVLINK_TRAP_TYPE_DROP
+		}
+	}
+	if !found {
+		t.Errorf("netdevsim trap source_mac_is_multicast not found in %+v", traps)
+	}
+
+	groups, err := GetTrapGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policers, err := GetTrapPolicers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) == 0 || len(policers) == 0 {
+		t.Errorf("got %d trap groups and %d policers, want netdevsim's", len(groups), len(policers))
+	}
+}
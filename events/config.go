@@ -0,0 +1,153 @@
+package events
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	defaultRetries        = 3
+	defaultRetryInterval  = time.Second
+	defaultSpoolMaxEvents = 10000
+	defaultSyslogTag      = "cumulus-exporter"
+)
+
+// Config is the events section of the configuration file
+type Config struct {
+	Webhooks []*WebhookConfig `yaml:"webhooks"`
+	Syslog   *SyslogConfig    `yaml:"syslog"`
+	// SpoolDir keeps the events not yet delivered to the webhooks on disk, so
+	// they survive webhook outages and restarts. Without it they are queued
+	// in memory and dropped once the retries are exhausted.
+	SpoolDir       string  `yaml:"spool_dir"`
+	SpoolMaxEvents int     `yaml:"spool_max_events"`
+	Rules          []*Rule `yaml:"rules"`
+}
+
+// WebhookConfig configures an URL the events are POSTed to as JSON
+type WebhookConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout time.Duration     `yaml:"timeout"`
+	// Retries is the number of times a failed delivery is retried, waiting
+	// RetryInterval before the first retry and twice as long before every
+	// further one
+	Retries       *int          `yaml:"retries"`
+	RetryInterval time.Duration `yaml:"retry_interval"`
+}
+
+// SyslogConfig configures writing the events to syslog. Without network and
+// address the local syslog daemon is used.
+type SyslogConfig struct {
+	Network string `yaml:"network"`
+	Address string `yaml:"address"`
+	Tag     string `yaml:"tag"`
+}
+
+// Rule describes the transitions of a metric's series that are reported as
+// events. By default a rule compares the value of every series with the one
+// of the previous collection. With Label set the series are identified by
+// their other labels and the value of Label is compared instead, which suits
+// info metrics like mstpd_state_info. An event is emitted if the previous
+// value matches From and the current one matches To (both regular
+// expressions, matching anything if empty).
+//
+// With Above or Below set, an event is emitted instead when the value
+// crosses the threshold. With Ratio set, the value compared is the ratio of
+// the series having the numerator and the denominator as value of the
+// Ratio label, e.g. of the asic collector's reading_type="current" and
+// reading_type="max" series.
+type Rule struct {
+	Name     string            `yaml:"name"`
+	Severity string            `yaml:"severity"`
+	Metric   string            `yaml:"metric"`
+	Match    map[string]string `yaml:"match"`
+	Label    string            `yaml:"label"`
+	From     string            `yaml:"from"`
+	To       string            `yaml:"to"`
+	Above    *float64          `yaml:"above"`
+	Below    *float64          `yaml:"below"`
+	Ratio    *RatioConfig      `yaml:"ratio"`
+}
+
+// RatioConfig names the label distinguishing the numerator and denominator
+// series of a ratio
+type RatioConfig struct {
+	Label       string `yaml:"label"`
+	Numerator   string `yaml:"numerator"`
+	Denominator string `yaml:"denominator"`
+}
+
+// Validate checks the configuration for errors
+func (c *Config) Validate() error {
+	for _, webhook := range c.Webhooks {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.Errorf("Invalid webhook URL '%s'", webhook.URL)
+		}
+		if webhook.Timeout < 0 || webhook.RetryInterval < 0 || (webhook.Retries != nil && *webhook.Retries < 0) {
+			return errors.Errorf("Timeout, retries and retry interval of webhook '%s' must not be negative", webhook.URL)
+		}
+	}
+	if c.SpoolMaxEvents < 0 {
+		return errors.New("spool_max_events must not be negative")
+	}
+
+	names := map[string]bool{}
+	for _, rule := range c.Rules {
+		err := rule.validate()
+		if err != nil {
+			return errors.Wrapf(err, "Invalid rule %s", rule.Name)
+		}
+		if names[rule.Name] {
+			return errors.Errorf("Duplicate rule %s", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	if r.Metric == "" {
+		return errors.New("metric must not be empty")
+	}
+	for label, expr := range r.Match {
+		_, err := regexp.Compile(expr)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid match of label %s", label)
+		}
+	}
+
+	threshold := r.Above != nil || r.Below != nil
+	if r.Above != nil && r.Below != nil {
+		return errors.New("only one of above and below may be set")
+	}
+	if threshold && (r.Label != "" || r.From != "" || r.To != "") {
+		return errors.New("label, from and to can not be combined with a threshold")
+	}
+	if r.Ratio != nil {
+		if !threshold {
+			return errors.New("ratio requires a threshold")
+		}
+		if r.Ratio.Label == "" || r.Ratio.Numerator == "" || r.Ratio.Denominator == "" {
+			return errors.New("ratio requires label, numerator and denominator")
+		}
+	}
+
+	_, err := regexp.Compile(r.From)
+	if err != nil {
+		return errors.Wrap(err, "Invalid from")
+	}
+	_, err = regexp.Compile(r.To)
+	if err != nil {
+		return errors.Wrap(err, "Invalid to")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event is a transition of a series as detected by a rule. It is POSTed to
+// the webhooks as JSON.
+type Event struct {
+	Rule      string            `json:"rule"`
+	Severity  string            `json:"severity,omitempty"`
+	Hostname  string            `json:"hostname"`
+	Collector string            `json:"collector"`
+	Metric    string            `json:"metric"`
+	Labels    map[string]string `json:"labels"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// String formats the event like a series, e.g.
+// psu_failed: hwmon_power_all_ok{sensor="PSU1"} changed from 1 to 0
+func (e *Event) String() string {
+	names := make([]string, 0, len(e.Labels))
+	for name := range e.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	labels := make([]string, 0, len(names))
+	for _, name := range names {
+		labels = append(labels, fmt.Sprintf("%s=%q", name, e.Labels[name]))
+	}
+	return fmt.Sprintf("%s: %s{%s} changed from %s to %s", e.Rule, e.Metric, strings.Join(labels, ","), e.From, e.To)
+}
@@ -0,0 +1,247 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/syslog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Labeler adds labels to the label sets of the series checked for events, so
+// events carry the labels added to the exposed series
+type Labeler func(series []map[string]string) error
+
+// Manager detects transitions in the metrics of every collection and sends
+// them to the configured webhooks and syslog
+type Manager struct {
+	rules    map[string][]*rule
+	webhooks []*webhook
+	syslog   *syslog.Writer
+	hostname string
+	labeler  Labeler
+
+	cancel    context.CancelFunc
+	waitGroup sync.WaitGroup
+}
+
+// New returns a Manager for cfg, or nil if cfg has no rules. labeler may be
+// nil. Events are only delivered to the webhooks once the Manager has been
+// started.
+func New(cfg *Config, labeler Labeler) (*Manager, error) {
+	if cfg == nil || len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+
+	m := &Manager{
+		rules:   map[string][]*rule{},
+		labeler: labeler,
+	}
+	for _, r := range cfg.Rules {
+		m.rules[r.Metric] = append(m.rules[r.Metric], compileRule(r))
+	}
+	m.hostname, _ = os.Hostname()
+
+	spoolMaxEvents := cfg.SpoolMaxEvents
+	if spoolMaxEvents == 0 {
+		spoolMaxEvents = defaultSpoolMaxEvents
+	}
+	for _, webhookConfig := range cfg.Webhooks {
+		webhookConfig := *webhookConfig
+		if webhookConfig.Timeout == 0 {
+			webhookConfig.Timeout = defaultWebhookTimeout
+		}
+		if webhookConfig.Retries == nil {
+			retries := defaultRetries
+			webhookConfig.Retries = &retries
+		}
+		if webhookConfig.RetryInterval == 0 {
+			webhookConfig.RetryInterval = defaultRetryInterval
+		}
+		w, err := newWebhook(&webhookConfig, cfg.SpoolDir, spoolMaxEvents)
+		if err != nil {
+			return nil, err
+		}
+		m.webhooks = append(m.webhooks, w)
+	}
+
+	if cfg.Syslog != nil {
+		tag := cfg.Syslog.Tag
+		if tag == "" {
+			tag = defaultSyslogTag
+		}
+		writer, err := syslog.Dial(cfg.Syslog.Network, cfg.Syslog.Address, syslog.LOG_NOTICE|syslog.LOG_DAEMON, tag)
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not connect to syslog")
+		}
+		m.syslog = writer
+	}
+	return m, nil
+}
+
+// Start starts delivering events to the webhooks
+func (m *Manager) Start() {
+	if m == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	for _, w := range m.webhooks {
+		m.waitGroup.Add(1)
+		go func(w *webhook) {
+			defer m.waitGroup.Done()
+			w.run(ctx)
+		}(w)
+	}
+}
+
+// Stop stops delivering events. Events not delivered yet are lost unless
+// they are spooled to disk.
+func (m *Manager) Stop() {
+	if m == nil {
+		return
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.waitGroup.Wait()
+	if m.syslog != nil {
+		m.syslog.Close()
+	}
+}
+
+// Observe checks the metrics of a collection of the collector registered as
+// collectorName for transitions and sends the resulting events
+func (m *Manager) Observe(collectorName string, metrics []prometheus.Metric) {
+	if m == nil {
+		return
+	}
+
+	var series []sample
+	for _, metric := range metrics {
+		name := metricName(metric.Desc())
+		if len(m.rules[name]) == 0 {
+			continue
+		}
+		s, err := newSample(metric)
+		if err != nil {
+			log.Warnf("Could not check metric %s for events: %v", metric.Desc(), err)
+			continue
+		}
+		s.metric = name
+		series = append(series, s)
+	}
+	if m.labeler != nil && len(series) > 0 {
+		labels := make([]map[string]string, len(series))
+		for i, s := range series {
+			labels[i] = s.labels
+		}
+		err := m.labeler(labels)
+		if err != nil {
+			log.Errorf("Could not add labels to metrics checked for events: %v", err)
+		}
+	}
+
+	samples := map[*rule][]sample{}
+	for _, s := range series {
+		for _, r := range m.rules[s.metric] {
+			if r.matches(s.labels) {
+				samples[r] = append(samples[r], s)
+			}
+		}
+	}
+
+	// every rule sees the collection, so series gone from it are forgotten
+	now := time.Now()
+	for _, rules := range m.rules {
+		for _, r := range rules {
+			for _, event := range r.observe(collectorName, samples[r]) {
+				event.Hostname = m.hostname
+				event.Collector = collectorName
+				event.Timestamp = now
+				m.send(event)
+			}
+		}
+	}
+}
+
+func (m *Manager) send(event *Event) {
+	log.Infof("Event %s", event)
+	if m.syslog != nil {
+		err := m.writeSyslog(event)
+		if err != nil {
+			log.Errorf("Could not write event to syslog: %v", err)
+		}
+	}
+
+	if len(m.webhooks) == 0 {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("Could not encode event: %v", err)
+		return
+	}
+	for _, w := range m.webhooks {
+		w.enqueue(data)
+	}
+}
+
+func (m *Manager) writeSyslog(event *Event) error {
+	message := event.String()
+	switch strings.ToLower(event.Severity) {
+	case "critical":
+		return m.syslog.Crit(message)
+	case "error":
+		return m.syslog.Err(message)
+	case "warning":
+		return m.syslog.Warning(message)
+	case "info":
+		return m.syslog.Info(message)
+	}
+	return m.syslog.Notice(message)
+}
+
+// metricName returns the name of the metrics described by desc. Desc does
+// not expose it other than through its string representation.
+func metricName(desc *prometheus.Desc) string {
+	_, name, found := strings.Cut(desc.String(), `fqName: "`)
+	if !found {
+		return ""
+	}
+	name, _, _ = strings.Cut(name, `"`)
+	return name
+}
+
+func newSample(metric prometheus.Metric) (sample, error) {
+	var m dto.Metric
+	err := metric.Write(&m)
+	if err != nil {
+		return sample{}, err
+	}
+
+	s := sample{
+		labels: map[string]string{},
+	}
+	for _, label := range m.Label {
+		s.labels[label.GetName()] = label.GetValue()
+	}
+	switch {
+	case m.Gauge != nil:
+		s.value = m.Gauge.GetValue()
+	case m.Counter != nil:
+		s.value = m.Counter.GetValue()
+	case m.Untyped != nil:
+		s.value = m.Untyped.GetValue()
+	default:
+		return sample{}, errors.New("Only gauges, counters and untyped metrics are supported")
+	}
+	return s, nil
+}
@@ -0,0 +1,181 @@
+package events
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sample is a single series of a collection
+type sample struct {
+	metric string
+	labels map[string]string
+	value  float64
+}
+
+// observation is the state of a series remembered from the previous collection
+type observation struct {
+	state string
+	value float64
+}
+
+// rule is a compiled Rule together with the series it has seen before
+type rule struct {
+	*Rule
+	match map[string]*regexp.Regexp
+	from  *regexp.Regexp
+	to    *regexp.Regexp
+
+	lock sync.Mutex
+	// collector is the name of the collector the rule's series come from
+	collector string
+	previous  map[string]observation
+}
+
+func compileRule(r *Rule) *rule {
+	res := &rule{
+		Rule:     r,
+		match:    map[string]*regexp.Regexp{},
+		from:     anchoredRegexp(r.From),
+		to:       anchoredRegexp(r.To),
+		previous: map[string]observation{},
+	}
+	for label, expr := range r.Match {
+		res.match[label] = anchoredRegexp(expr)
+	}
+	return res
+}
+
+// anchoredRegexp compiles the validated expression expr, matching anything if it is empty
+func anchoredRegexp(expr string) *regexp.Regexp {
+	if expr == "" {
+		expr = ".*"
+	}
+	return regexp.MustCompile("^(?:" + expr + ")$")
+}
+
+func (r *rule) matches(labels map[string]string) bool {
+	for label, expr := range r.match {
+		if !expr.MatchString(labels[label]) {
+			return false
+		}
+	}
+	return true
+}
+
+// observe compares samples of a collection of collectorName with the previous
+// collection and returns the events for the series that changed. Series seen
+// for the first time only establish their state, series missing from samples
+// are forgotten.
+func (r *rule) observe(collectorName string, samples []sample) []*Event {
+	if r.Ratio != nil {
+		samples = r.ratios(samples)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(samples) == 0 && r.collector != collectorName {
+		// the collection is not the one of the rule's series
+		return nil
+	}
+	r.collector = collectorName
+
+	var res []*Event
+	current := make(map[string]observation, len(samples))
+	for _, s := range samples {
+		labels := s.labels
+		state := formatValue(s.value)
+		if r.Label != "" {
+			state = labels[r.Label]
+			labels = without(labels, r.Label)
+		}
+
+		key := seriesKey(labels)
+		previous, found := r.previous[key]
+		current[key] = observation{state: state, value: s.value}
+		if !found || !r.changed(previous, state, s.value) {
+			continue
+		}
+		res = append(res, &Event{
+			Rule:     r.Name,
+			Severity: r.Severity,
+			Metric:   r.Metric,
+			Labels:   labels,
+			From:     previous.state,
+			To:       state,
+		})
+	}
+	r.previous = current
+	return res
+}
+
+func (r *rule) changed(previous observation, state string, value float64) bool {
+	switch {
+	case r.Above != nil:
+		return previous.value <= *r.Above && value > *r.Above
+	case r.Below != nil:
+		return previous.value >= *r.Below && value < *r.Below
+	}
+	return previous.state != state && r.from.MatchString(previous.state) && r.to.MatchString(state)
+}
+
+// ratios combines the numerator and denominator series into a sample of
+// their ratio without the ratio label
+func (r *rule) ratios(samples []sample) []sample {
+	numerators := map[string]sample{}
+	denominators := map[string]float64{}
+	for _, s := range samples {
+		labels := without(s.labels, r.Ratio.Label)
+		key := seriesKey(labels)
+		switch s.labels[r.Ratio.Label] {
+		case r.Ratio.Numerator:
+			numerators[key] = sample{labels: labels, value: s.value}
+		case r.Ratio.Denominator:
+			denominators[key] = s.value
+		}
+	}
+
+	var res []sample
+	for key, numerator := range numerators {
+		denominator, found := denominators[key]
+		if !found || denominator == 0 {
+			continue
+		}
+		res = append(res, sample{labels: numerator.labels, value: numerator.value / denominator})
+	}
+	return res
+}
+
+func without(labels map[string]string, name string) map[string]string {
+	res := make(map[string]string, len(labels))
+	for label, value := range labels {
+		if label != name {
+			res[label] = value
+		}
+	}
+	return res
+}
+
+func seriesKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[name]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
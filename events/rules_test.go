@@ -0,0 +1,175 @@
+package events
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func series(value float64, labels ...string) sample {
+	s := sample{
+		labels: map[string]string{},
+		value:  value,
+	}
+	for i := 0; i < len(labels); i += 2 {
+		s.labels[labels[i]] = labels[i+1]
+	}
+	return s
+}
+
+func TestRuleObserve(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        *Rule
+		collections [][]sample
+		events      []string
+	}{
+		{
+			name: "first seen",
+			rule: &Rule{Name: "psu_failed", Metric: "m", From: "1", To: "0"},
+			collections: [][]sample{
+				{series(0, "psu", "PSU1")},
+			},
+		},
+		{
+			name: "value transition",
+			rule: &Rule{Name: "psu_failed", Metric: "m", From: "1", To: "0"},
+			collections: [][]sample{
+				{series(1, "psu", "PSU1"), series(1, "psu", "PSU2")},
+				{series(0, "psu", "PSU1"), series(1, "psu", "PSU2")},
+				{series(0, "psu", "PSU1"), series(1, "psu", "PSU2")},
+				{series(1, "psu", "PSU1"), series(1, "psu", "PSU2")},
+			},
+			events: []string{`psu_failed: m{psu="PSU1"} changed from 1 to 0`},
+		},
+		{
+			name: "label transition",
+			rule: &Rule{Name: "left_forwarding", Metric: "m", Label: "state", From: "forwarding"},
+			collections: [][]sample{
+				{series(1, "interface", "swp1", "state", "forwarding"), series(1, "interface", "swp2", "state", "learning")},
+				{series(1, "interface", "swp1", "state", "discarding"), series(1, "interface", "swp2", "state", "forwarding")},
+			},
+			events: []string{`left_forwarding: m{interface="swp1"} changed from forwarding to discarding`},
+		},
+		{
+			name: "to mismatch",
+			rule: &Rule{Name: "r", Metric: "m", Label: "state", From: "forwarding", To: "disc.*"},
+			collections: [][]sample{
+				{series(1, "interface", "swp1", "state", "forwarding")},
+				{series(1, "interface", "swp1", "state", "learning")},
+			},
+		},
+		{
+			name: "above",
+			rule: &Rule{Name: "r", Metric: "m", Above: float(0.9)},
+			collections: [][]sample{
+				{series(0.5)},
+				{series(0.9)},
+				{series(0.95)},
+				{series(0.97)},
+				{series(0.5)},
+				{series(1)},
+			},
+			events: []string{
+				`r: m{} changed from 0.9 to 0.95`,
+				`r: m{} changed from 0.5 to 1`,
+			},
+		},
+		{
+			name: "below",
+			rule: &Rule{Name: "r", Metric: "m", Below: float(10)},
+			collections: [][]sample{
+				{series(12)},
+				{series(9)},
+				{series(8)},
+			},
+			events: []string{`r: m{} changed from 12 to 9`},
+		},
+		{
+			name: "ratio",
+			rule: &Rule{Name: "r", Metric: "m", Above: float(0.5), Ratio: &RatioConfig{Label: "type", Numerator: "current", Denominator: "max"}},
+			collections: [][]sample{
+				{series(10, "table", "a", "type", "current"), series(100, "table", "a", "type", "max")},
+				{series(60, "table", "a", "type", "current"), series(100, "table", "a", "type", "max")},
+			},
+			events: []string{`r: m{table="a"} changed from 0.1 to 0.6`},
+		},
+		{
+			name: "ratio without denominator",
+			rule: &Rule{Name: "r", Metric: "m", Above: float(0.5), Ratio: &RatioConfig{Label: "type", Numerator: "current", Denominator: "max"}},
+			collections: [][]sample{
+				{series(10, "table", "a", "type", "current"), series(100, "table", "a", "type", "max")},
+				{series(60, "table", "a", "type", "current")},
+				{series(60, "table", "a", "type", "current"), series(100, "table", "a", "type", "max")},
+			},
+		},
+		{
+			name: "ratio with zero denominator",
+			rule: &Rule{Name: "r", Metric: "m", Above: float(0.5), Ratio: &RatioConfig{Label: "type", Numerator: "current", Denominator: "max"}},
+			collections: [][]sample{
+				{series(0, "table", "a", "type", "current"), series(0, "table", "a", "type", "max")},
+				{series(60, "table", "a", "type", "current"), series(100, "table", "a", "type", "max")},
+			},
+		},
+		{
+			name: "match",
+			rule: &Rule{Name: "r", Metric: "m", Label: "state", Match: map[string]string{"interface": "swp.*"}},
+			collections: [][]sample{
+				{series(1, "interface", "swp1", "state", "forwarding"), series(1, "interface", "peerlink", "state", "forwarding")},
+				{series(1, "interface", "swp1", "state", "discarding"), series(1, "interface", "peerlink", "state", "discarding")},
+			},
+			events: []string{`r: m{interface="swp1"} changed from forwarding to discarding`},
+		},
+		{
+			name: "series gone",
+			rule: &Rule{Name: "r", Metric: "m", Label: "state"},
+			collections: [][]sample{
+				{series(1, "interface", "swp1", "state", "forwarding"), series(1, "interface", "swp2", "state", "forwarding")},
+				{series(1, "interface", "swp2", "state", "forwarding")},
+				{},
+				{series(1, "interface", "swp1", "state", "discarding"), series(1, "interface", "swp2", "state", "discarding")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := compileRule(test.rule)
+			var events []string
+			for _, collection := range test.collections {
+				var samples []sample
+				for _, s := range collection {
+					if r.matches(s.labels) {
+						samples = append(samples, s)
+					}
+				}
+				for _, event := range r.observe("test", samples) {
+					events = append(events, event.String())
+				}
+			}
+			sort.Strings(events)
+			sort.Strings(test.events)
+			if len(events) == 0 && len(test.events) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(events, test.events) {
+				t.Errorf("got events %q, want %q", events, test.events)
+			}
+		})
+	}
+}
+
+func TestRuleObserveOtherCollector(t *testing.T) {
+	r := compileRule(&Rule{Name: "r", Metric: "m", From: "1", To: "0"})
+	r.observe("hwmon", []sample{series(1, "psu", "PSU1")})
+	// collections of other collectors do not have the rule's series
+	r.observe("asic", nil)
+	events := r.observe("hwmon", []sample{series(0, "psu", "PSU1")})
+	if len(events) != 1 {
+		t.Errorf("got %d events, want 1", len(events))
+	}
+}
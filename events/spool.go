@@ -0,0 +1,184 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// spool queues the encoded events of a webhook until they are delivered
+type spool interface {
+	// push appends an event, dropping the oldest one if the spool is full
+	push(data []byte) error
+	// peek returns the oldest event and its id, ok is false if the spool is empty
+	peek() (id string, data []byte, ok bool, err error)
+	// remove removes the event returned by peek
+	remove(id string) error
+	// persistent is true if the events survive a restart
+	persistent() bool
+}
+
+// memorySpool queues events in memory
+type memorySpool struct {
+	lock      sync.Mutex
+	maxEvents int
+	events    [][]byte
+	first     uint64
+}
+
+func newMemorySpool(maxEvents int) *memorySpool {
+	return &memorySpool{
+		maxEvents: maxEvents,
+	}
+}
+
+func (s *memorySpool) push(data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.events) >= s.maxEvents {
+		log.Warn("Event queue is full, dropping the oldest event")
+		s.events = s.events[1:]
+		s.first++
+	}
+	s.events = append(s.events, data)
+	return nil
+}
+
+func (s *memorySpool) peek() (string, []byte, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.events) == 0 {
+		return "", nil, false, nil
+	}
+	return strconv.FormatUint(s.first, 10), s.events[0], true, nil
+}
+
+func (s *memorySpool) remove(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// the event may have been dropped in the meantime
+	if len(s.events) == 0 || strconv.FormatUint(s.first, 10) != id {
+		return nil
+	}
+	s.events = s.events[1:]
+	s.first++
+	return nil
+}
+
+func (*memorySpool) persistent() bool {
+	return false
+}
+
+// dirSpool queues events as files in a directory, named so that their
+// lexical order is the order they were pushed in
+type dirSpool struct {
+	lock      sync.Mutex
+	dir       string
+	maxEvents int
+	sequence  uint64
+}
+
+func newDirSpool(dir string, maxEvents int) (*dirSpool, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not create spool directory '%s'", dir)
+	}
+	// partial events left behind by a crash
+	partialFiles, _ := filepath.Glob(filepath.Join(dir, ".*.json"))
+	for _, file := range partialFiles {
+		_ = os.Remove(file)
+	}
+	return &dirSpool{
+		dir:       dir,
+		maxEvents: maxEvents,
+	}, nil
+}
+
+func (s *dirSpool) push(data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	names, err := s.list()
+	if err != nil {
+		return err
+	}
+	for len(names) >= s.maxEvents {
+		log.Warnf("Event spool '%s' is full, dropping the oldest event", s.dir)
+		err = os.Remove(filepath.Join(s.dir, names[0]))
+		if err != nil {
+			return errors.Wrap(err, "Could not drop spooled event")
+		}
+		names = names[1:]
+	}
+
+	s.sequence++
+	name := fmt.Sprintf("%020d-%010d.json", time.Now().UnixNano(), s.sequence)
+	// events are written to a temporary file first, so that a crash does not
+	// leave a partial event behind
+	tmpFile := filepath.Join(s.dir, "."+name)
+	err = os.WriteFile(tmpFile, data, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "Could not spool event")
+	}
+	err = os.Rename(tmpFile, filepath.Join(s.dir, name))
+	if err != nil {
+		return errors.Wrap(err, "Could not spool event")
+	}
+	return nil
+}
+
+func (s *dirSpool) peek() (string, []byte, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	names, err := s.list()
+	if err != nil || len(names) == 0 {
+		return "", nil, false, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, names[0]))
+	if err != nil {
+		return "", nil, false, errors.Wrap(err, "Could not read spooled event")
+	}
+	return names[0], data, true, nil
+}
+
+func (s *dirSpool) remove(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := os.Remove(filepath.Join(s.dir, id))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Could not remove delivered event")
+	}
+	return nil
+}
+
+func (*dirSpool) persistent() bool {
+	return true
+}
+
+// list returns the names of the spooled events, oldest first
+func (s *dirSpool) list() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not read spool directory '%s'", s.dir)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), ".json") || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	return names, nil
+}
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxRetryInterval caps the time waited between retries
+const maxRetryInterval = 5 * time.Minute
+
+// backoff doubles the time waited after every failure, starting at initial and
+// capped at maxRetryInterval
+type backoff struct {
+	initial time.Duration
+	next    time.Duration
+}
+
+// failed returns the time to wait after a failure
+func (b *backoff) failed() time.Duration {
+	interval := b.next
+	if interval == 0 {
+		interval = b.initial
+	}
+	b.next = min(2*interval, maxRetryInterval)
+	return interval
+}
+
+// succeeded starts over at the initial interval
+func (b *backoff) succeeded() {
+	b.next = 0
+}
+
+// permanentError is returned for deliveries that are not worth retrying
+type permanentError struct {
+	error
+}
+
+// webhook delivers the spooled events to an URL one after the other
+type webhook struct {
+	config *WebhookConfig
+	client *http.Client
+	spool  spool
+	notify chan struct{}
+}
+
+func newWebhook(config *WebhookConfig, spoolDir string, spoolMaxEvents int) (*webhook, error) {
+	w := &webhook{
+		config: config,
+		client: &http.Client{
+			Timeout: config.Timeout,
+		},
+		notify: make(chan struct{}, 1),
+	}
+	if spoolDir == "" {
+		w.spool = newMemorySpool(spoolMaxEvents)
+		return w, nil
+	}
+
+	// every webhook has a directory of its own, named after its URL so that
+	// its events are picked up again after a restart
+	hash := sha256.Sum256([]byte(config.URL))
+	spool, err := newDirSpool(filepath.Join(spoolDir, hex.EncodeToString(hash[:8])), spoolMaxEvents)
+	if err != nil {
+		return nil, err
+	}
+	w.spool = spool
+	return w, nil
+}
+
+func (w *webhook) enqueue(data []byte) {
+	err := w.spool.push(data)
+	if err != nil {
+		log.Errorf("Could not queue event for webhook %s: %v", w.config.URL, err)
+		return
+	}
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers events until ctx is cancelled. Events failing to be delivered
+// after all retries are dropped, unless they are spooled to disk, in which
+// case their delivery is retried until it succeeds, waiting exponentially
+// longer after every failed round of retries.
+func (w *webhook) run(ctx context.Context) {
+	failures := backoff{initial: w.config.RetryInterval}
+	for {
+		id, data, ok, err := w.spool.peek()
+		if err != nil {
+			log.Errorf("Could not read event queue of webhook %s: %v", w.config.URL, err)
+			if !sleep(ctx, failures.failed()) {
+				return
+			}
+			continue
+		}
+		if !ok {
+			select {
+			case <-w.notify:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		err = w.deliverWithRetries(ctx, data)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			var permanent permanentError
+			if w.spool.persistent() && !errors.As(err, &permanent) {
+				interval := failures.failed()
+				log.Errorf("Could not deliver event to webhook %s, keeping it spooled and retrying in %s: %v", w.config.URL, interval, err)
+				if !sleep(ctx, interval) {
+					return
+				}
+				continue
+			}
+			log.Errorf("Could not deliver event to webhook %s, dropping it: %v", w.config.URL, err)
+		}
+
+		err = w.spool.remove(id)
+		if err != nil {
+			log.Errorf("Could not remove event from queue of webhook %s: %v", w.config.URL, err)
+			if !sleep(ctx, failures.failed()) {
+				return
+			}
+			continue
+		}
+		failures.succeeded()
+	}
+}
+
+func (w *webhook) deliverWithRetries(ctx context.Context, data []byte) error {
+	retries := backoff{initial: w.config.RetryInterval}
+	for retry := 0; ; retry++ {
+		err := w.deliver(ctx, data)
+		if err == nil {
+			return nil
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) || retry >= *w.config.Retries {
+			return err
+		}
+
+		interval := retries.failed()
+		log.Warnf("Could not deliver event to webhook %s, retrying in %s: %v", w.config.URL, interval, err)
+		if !sleep(ctx, interval) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *webhook) deliver(ctx context.Context, data []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(data))
+	if err != nil {
+		return permanentError{errors.Wrap(err, "Could not create request")}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range w.config.Headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("webhook responded with %s", response.Status)
+	// client errors will not go away by retrying, except for rate limiting
+	if response.StatusCode >= 400 && response.StatusCode < 500 && response.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
+
+// sleep waits for d and returns false if ctx was cancelled in the meantime
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
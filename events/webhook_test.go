@@ -0,0 +1,83 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	b := backoff{initial: time.Minute}
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, maxRetryInterval, maxRetryInterval}
+	for i, interval := range expected {
+		if got := b.failed(); got != interval {
+			t.Errorf("failure %d: got %s, want %s", i+1, got, interval)
+		}
+	}
+
+	b.succeeded()
+	if got := b.failed(); got != time.Minute {
+		t.Errorf("failure after success: got %s, want %s", got, time.Minute)
+	}
+}
+
+// TestWebhookSpoolRetries checks that spooled events are retried after the
+// retries are exhausted, with intervals growing from the retry interval
+func TestWebhookSpoolRetries(t *testing.T) {
+	var lock sync.Mutex
+	var attempts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts = append(attempts, time.Now())
+		if len(attempts) < 4 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	retries := 0
+	w, err := newWebhook(&WebhookConfig{URL: server.URL, Retries: &retries, RetryInterval: 20 * time.Millisecond}, t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.enqueue([]byte(`{}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		w.run(ctx)
+		close(done)
+	}()
+
+	for {
+		_, _, ok, err := w.spool.peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatal("spooled event was not delivered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(attempts) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(attempts))
+	}
+	for i, minimum := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond} {
+		if waited := attempts[i+1].Sub(attempts[i]); waited < minimum {
+			t.Errorf("attempt %d: waited %s, want at least %s", i+2, waited, minimum)
+		}
+	}
+}
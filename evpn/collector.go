@@ -0,0 +1,195 @@
+package evpn
+
+import (
+	"context"
+	"flag"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/frr"
+)
+
+const prefix = "evpn_vni_"
+
+var (
+	vtyshPath = flag.String("collector.evpn.vtysh-path", "/usr/bin/vtysh", "vtysh binary path")
+
+	infoDesc                   *prometheus.Desc
+	remoteVtepsDesc            *prometheus.Desc
+	macsDesc                   *prometheus.Desc
+	arpNdEntriesDesc           *prometheus.Desc
+	duplicateMacsDesc          *prometheus.Desc
+	macDetectionCountDesc      *prometheus.Desc
+	movedMacsDesc              *prometheus.Desc
+	macMobilitySequenceSumDesc *prometheus.Desc
+	macMobilitySequenceMaxDesc *prometheus.Desc
+)
+
+// Config configures the evpn collector
+type Config struct {
+	collector.Settings `yaml:",inline"`
+	VtyshPath          string `yaml:"vtysh_path"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	if c.VtyshPath == "" {
+		return errors.New("vtysh_path must not be empty")
+	}
+	return nil
+}
+
+// Collector collects EVPN / VXLAN control plane metrics exposed by FRR's vtysh
+type Collector struct {
+	vtyshPath string
+}
+
+// NewCollector returns a new Collector instance
+func NewCollector(vtyshPath string) *Collector {
+	return &Collector{
+		vtyshPath: vtyshPath,
+	}
+}
+
+func init() {
+	collector.Register("evpn", false, func() collector.Config {
+		return &Config{
+			VtyshPath: *vtyshPath,
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		return NewCollector(cfg.(*Config).VtyshPath), nil
+	})
+
+	labels := []string{"vni"}
+	infoDesc = prometheus.NewDesc(prefix+"info", "type (L2 / L3), VRF, VXLAN interface and local VTEP of the VNI", append(labels, "type", "vrf", "vxlan_interface", "local_vtep"), nil)
+	remoteVtepsDesc = prometheus.NewDesc(prefix+"remote_vteps", "number of remote VTEPs", labels, nil)
+	macsDesc = prometheus.NewDesc(prefix+"macs", "number of MACs learned locally or from remote VTEPs", append(labels, "location"), nil)
+	arpNdEntriesDesc = prometheus.NewDesc(prefix+"arp_nd_entries", "number of ARP / ND entries learned locally or from remote VTEPs", append(labels, "location"), nil)
+	duplicateMacsDesc = prometheus.NewDesc(prefix+"duplicate_macs", "number of MACs flagged as duplicate by duplicate address detection", labels, nil)
+	macDetectionCountDesc = prometheus.NewDesc(prefix+"mac_detection_count", "sum of the duplicate address detection move counters of all MACs", labels, nil)
+	movedMacsDesc = prometheus.NewDesc(prefix+"moved_macs", "number of MACs with a MAC mobility sequence number above 0", labels, nil)
+	macMobilitySequenceSumDesc = prometheus.NewDesc(prefix+"mac_mobility_sequence_sum", "sum of the MAC mobility sequence numbers of all MACs", labels, nil)
+	macMobilitySequenceMaxDesc = prometheus.NewDesc(prefix+"mac_mobility_sequence_max", "highest MAC mobility sequence number of all MACs", labels, nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- infoDesc
+	ch <- remoteVtepsDesc
+	ch <- macsDesc
+	ch <- arpNdEntriesDesc
+	ch <- duplicateMacsDesc
+	ch <- macDetectionCountDesc
+	ch <- movedMacsDesc
+	ch <- macMobilitySequenceSumDesc
+	ch <- macMobilitySequenceMaxDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	details := VniDetails{}
+	err := frr.ShowJSON(ctx, c.vtyshPath, "show evpn vni detail json", &details)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve EVPN VNI details")
+		return
+	}
+
+	macTable := MacTable{}
+	err = frr.ShowJSON(ctx, c.vtyshPath, "show evpn mac vni all json", &macTable)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve EVPN MAC table")
+		return
+	}
+
+	arpCache := ArpCache{}
+	err = frr.ShowJSON(ctx, c.vtyshPath, "show evpn arp-cache vni all json", &arpCache)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve EVPN ARP / ND cache")
+		return
+	}
+
+	for _, detail := range details {
+		if detail == nil {
+			continue
+		}
+		collectForVni(detail, metrics)
+	}
+	for vni, macs := range macTable {
+		if macs == nil {
+			continue
+		}
+		collectMacsForVni(vni, macs, metrics)
+	}
+	for vni, neighbors := range arpCache {
+		if neighbors == nil {
+			continue
+		}
+		collectNeighborsForVni(vni, neighbors, metrics)
+	}
+}
+
+// Name returns the string "EvpnCollector"
+func (*Collector) Name() string {
+	return "EvpnCollector"
+}
+
+func collectForVni(detail *VniDetail, metrics chan<- prometheus.Metric) {
+	vni := strconv.FormatFloat(detail.Vni, 'f', -1, 64)
+	metrics <- prometheus.MustNewConstMetric(infoDesc, prometheus.GaugeValue, 1.0, vni, detail.Type, detail.GetVrf(), detail.GetVxlanInterface(), detail.GetLocalVtep())
+	if detail.NumRemoteVteps != nil {
+		metrics <- prometheus.MustNewConstMetric(remoteVtepsDesc, prometheus.GaugeValue, *detail.NumRemoteVteps, vni)
+	}
+}
+
+func collectMacsForVni(vni string, macs *VniMacs, metrics chan<- prometheus.Metric) {
+	local, remote := 0.0, 0.0
+	duplicates, detections, moved, sequenceSum, sequenceMax := 0.0, 0.0, 0.0, 0.0, 0.0
+	for _, mac := range macs.Macs {
+		if mac == nil {
+			continue
+		}
+		switch mac.Type {
+		case "local":
+			local++
+		case "remote":
+			remote++
+		}
+		if mac.IsDuplicate {
+			duplicates++
+		}
+		detections += mac.DetectionCount
+
+		sequence := math.Max(mac.LocalSequence, mac.RemoteSequence)
+		if sequence > 0 {
+			moved++
+		}
+		sequenceSum += sequence
+		sequenceMax = math.Max(sequenceMax, sequence)
+	}
+
+	metrics <- prometheus.MustNewConstMetric(macsDesc, prometheus.GaugeValue, local, vni, "local")
+	metrics <- prometheus.MustNewConstMetric(macsDesc, prometheus.GaugeValue, remote, vni, "remote")
+	metrics <- prometheus.MustNewConstMetric(duplicateMacsDesc, prometheus.GaugeValue, duplicates, vni)
+	metrics <- prometheus.MustNewConstMetric(macDetectionCountDesc, prometheus.GaugeValue, detections, vni)
+	metrics <- prometheus.MustNewConstMetric(movedMacsDesc, prometheus.GaugeValue, moved, vni)
+	metrics <- prometheus.MustNewConstMetric(macMobilitySequenceSumDesc, prometheus.GaugeValue, sequenceSum, vni)
+	metrics <- prometheus.MustNewConstMetric(macMobilitySequenceMaxDesc, prometheus.GaugeValue, sequenceMax, vni)
+}
+
+func collectNeighborsForVni(vni string, neighbors *VniNeighbors, metrics chan<- prometheus.Metric) {
+	local, remote := 0.0, 0.0
+	for _, neighbor := range neighbors.Neighbors {
+		switch neighbor.Type {
+		case "local":
+			local++
+		case "remote":
+			remote++
+		}
+	}
+
+	metrics <- prometheus.MustNewConstMetric(arpNdEntriesDesc, prometheus.GaugeValue, local, vni, "local")
+	metrics <- prometheus.MustNewConstMetric(arpNdEntriesDesc, prometheus.GaugeValue, remote, vni, "remote")
+}
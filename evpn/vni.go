@@ -0,0 +1,139 @@
+package evpn
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// VniDetails stores the parsed output of "show evpn vni detail json". FRR
+// prints a list of VNIs, older releases an object indexed by VNI.
+type VniDetails []*VniDetail
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *VniDetails) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		var list []*VniDetail
+		err := json.Unmarshal(b, &list)
+		if err != nil {
+			return err
+		}
+		*d = list
+		return nil
+	}
+
+	var byVni map[string]*VniDetail
+	err := json.Unmarshal(b, &byVni)
+	if err != nil {
+		return err
+	}
+	*d = VniDetails{}
+	for _, detail := range byVni {
+		*d = append(*d, detail)
+	}
+	return nil
+}
+
+// VniDetail stores the details of a single L2 or L3 VNI. L2 and L3 VNIs name
+// some of their fields differently, use the accessor functions for these.
+type VniDetail struct {
+	Vni            float64  `json:"vni"`
+	Type           string   `json:"type"`
+	TenantVrf      string   `json:"tenantVrf"`
+	Vrf            string   `json:"vrf"`
+	VxlanInterface string   `json:"vxlanInterface"`
+	VxlanIntf      string   `json:"vxlanIntf"`
+	VtepIP         string   `json:"vtepIp"`
+	LocalVtepIP    string   `json:"localVtepIp"`
+	NumRemoteVteps *float64 `json:"numRemoteVteps"`
+}
+
+// GetVrf returns the VRF of the VNI
+func (d *VniDetail) GetVrf() string {
+	if d.TenantVrf != "" {
+		return d.TenantVrf
+	}
+	return d.Vrf
+}
+
+// GetVxlanInterface returns the VXLAN interface of the VNI
+func (d *VniDetail) GetVxlanInterface() string {
+	if d.VxlanInterface != "" {
+		return d.VxlanInterface
+	}
+	return d.VxlanIntf
+}
+
+// GetLocalVtep returns the local VTEP IP of the VNI
+func (d *VniDetail) GetLocalVtep() string {
+	if d.VtepIP != "" {
+		return d.VtepIP
+	}
+	return d.LocalVtepIP
+}
+
+// MacTable stores the parsed output of "show evpn mac vni all json", indexed by VNI
+type MacTable map[string]*VniMacs
+
+// VniMacs stores the MACs learned within a single VNI
+type VniMacs struct {
+	NumMacs float64         `json:"numMacs"`
+	Macs    map[string]*Mac `json:"macs"`
+}
+
+// Mac stores a single MAC entry
+type Mac struct {
+	Type           string  `json:"type"`
+	RemoteVtep     string  `json:"remoteVtep"`
+	LocalSequence  float64 `json:"localSequence"`
+	RemoteSequence float64 `json:"remoteSequence"`
+	DetectionCount float64 `json:"detectionCount"`
+	IsDuplicate    bool    `json:"isDuplicate"`
+}
+
+// ArpCache stores the parsed output of "show evpn arp-cache vni all json",
+// indexed by VNI
+type ArpCache map[string]*VniNeighbors
+
+// VniNeighbors stores the ARP / ND entries of a single VNI. FRR prints the
+// entries indexed by IP next to the numArpNd counter.
+type VniNeighbors struct {
+	NumArpNd  float64
+	Neighbors map[string]*Neighbor
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *VniNeighbors) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(b, &fields)
+	if err != nil {
+		return err
+	}
+
+	n.Neighbors = map[string]*Neighbor{}
+	for key, value := range fields {
+		if key == "numArpNd" {
+			err = json.Unmarshal(value, &n.NumArpNd)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		neighbor := &Neighbor{}
+		err = json.Unmarshal(value, neighbor)
+		if err != nil {
+			return err
+		}
+		n.Neighbors[key] = neighbor
+	}
+	return nil
+}
+
+// Neighbor stores a single ARP / ND entry
+type Neighbor struct {
+	Type       string `json:"type"`
+	State      string `json:"state"`
+	Mac        string `json:"mac"`
+	RemoteVtep string `json:"remoteVtep"`
+}
@@ -0,0 +1,107 @@
+package evpn
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestVniDetailsUnmarshal(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		vnis   []float64
+	}{
+		{
+			name: "list",
+			output: `[
+  {"vni": 1000, "type": "L2", "tenantVrf": "RED", "vxlanInterface": "vni1000", "vtepIp": "10.0.0.11", "numRemoteVteps": 2},
+  {"vni": 4001, "type": "L3", "vrf": "RED", "vxlanIntf": "vni4001", "localVtepIp": "10.0.0.11"}
+]`,
+			vnis: []float64{1000, 4001},
+		},
+		{
+			name: "object",
+			output: `{
+  "1000": {"vni": 1000, "type": "L2", "tenantVrf": "RED", "vxlanInterface": "vni1000", "vtepIp": "10.0.0.11", "numRemoteVteps": 2},
+  "4001": {"vni": 4001, "type": "L3", "vrf": "RED", "vxlanIntf": "vni4001", "localVtepIp": "10.0.0.11"}
+}`,
+			vnis: []float64{1000, 4001},
+		},
+		{name: "empty list", output: `[]`},
+		{name: "empty object", output: `{}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			details := VniDetails{}
+			err := json.Unmarshal([]byte(test.output), &details)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var vnis []float64
+			for _, detail := range details {
+				vnis = append(vnis, detail.Vni)
+				if detail.GetVrf() != "RED" || detail.GetLocalVtep() != "10.0.0.11" || detail.GetVxlanInterface() != "vni"+strconv.FormatFloat(detail.Vni, 'f', -1, 64) {
+					t.Errorf("VNI %v: got VRF %q, local VTEP %q and VXLAN interface %q", detail.Vni, detail.GetVrf(), detail.GetLocalVtep(), detail.GetVxlanInterface())
+				}
+			}
+			sort.Float64s(vnis)
+			if !reflect.DeepEqual(vnis, test.vnis) {
+				t.Errorf("got VNIs %v, want %v", vnis, test.vnis)
+			}
+		})
+	}
+
+	details := VniDetails{}
+	err := json.Unmarshal([]byte(`"1000"`), &details)
+	if err == nil {
+		t.Error("unmarshaling a string succeeded")
+	}
+}
+
+func TestVniNeighborsUnmarshal(t *testing.T) {
+	output := `{
+  "1000": {
+    "numArpNd": 2,
+    "10.1.10.21": {"type": "local", "state": "active", "mac": "44:38:39:00:00:21", "localSequence": 0},
+    "10.1.10.31": {"type": "remote", "state": "active", "mac": "44:38:39:00:00:31", "remoteVtep": "10.0.0.12"}
+  },
+  "2000": {
+    "numArpNd": 0
+  }
+}`
+	arpCache := ArpCache{}
+	err := json.Unmarshal([]byte(output), &arpCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ArpCache{
+		"1000": {
+			NumArpNd: 2,
+			Neighbors: map[string]*Neighbor{
+				"10.1.10.21": {Type: "local", State: "active", Mac: "44:38:39:00:00:21"},
+				"10.1.10.31": {Type: "remote", State: "active", Mac: "44:38:39:00:00:31", RemoteVtep: "10.0.0.12"},
+			},
+		},
+		"2000": {
+			Neighbors: map[string]*Neighbor{},
+		},
+	}
+	if !reflect.DeepEqual(arpCache, expected) {
+		t.Errorf("got %+v, want %+v", arpCache, expected)
+	}
+
+	neighbors := &VniNeighbors{}
+	err = json.Unmarshal([]byte(`{"numArpNd": "x"}`), neighbors)
+	if err == nil {
+		t.Error("unmarshaling an invalid counter succeeded")
+	}
+	err = json.Unmarshal([]byte(`{"10.1.10.21": []}`), neighbors)
+	if err == nil {
+		t.Error("unmarshaling an invalid entry succeeded")
+	}
+}
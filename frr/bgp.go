@@ -0,0 +1,88 @@
+package frr
+
+import (
+	"encoding/json"
+)
+
+// BgpSummary stores the parsed output of "show bgp vrf all summary json",
+// indexed by VRF and AFI/SAFI (e.g. ipv4Unicast, l2VpnEvpn)
+type BgpSummary map[string]map[string]*BgpAfiSafiSummary
+
+// BgpAfiSafiSummary stores the summary of a single AFI/SAFI within a VRF
+type BgpAfiSafiSummary struct {
+	RouterID string                     `json:"routerId"`
+	AS       float64                    `json:"as"`
+	Peers    map[string]*BgpPeerSummary `json:"peers"`
+}
+
+// BgpPeerSummary stores the summary of a single peer within an AFI/SAFI
+type BgpPeerSummary struct {
+	Hostname               string  `json:"hostname"`
+	RemoteAS               float64 `json:"remoteAs"`
+	LocalAS                float64 `json:"localAs"`
+	PeerUptimeMsec         float64 `json:"peerUptimeMsec"`
+	PfxRcd                 float64 `json:"pfxRcd"`
+	PfxSnt                 float64 `json:"pfxSnt"`
+	State                  string  `json:"state"`
+	ConnectionsEstablished float64 `json:"connectionsEstablished"`
+	ConnectionsDropped     float64 `json:"connectionsDropped"`
+	Description            string  `json:"desc"`
+}
+
+// BgpNeighbors stores the parsed output of "show bgp vrf all neighbors json",
+// indexed by VRF and peer
+type BgpNeighbors map[string]BgpVrfNeighbors
+
+// BgpVrfNeighbors stores the neighbors of a single VRF. FRR puts the VRF's
+// id and name next to the peers, these entries are skipped.
+type BgpVrfNeighbors map[string]*BgpNeighbor
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *BgpVrfNeighbors) UnmarshalJSON(b []byte) error {
+	var entries map[string]json.RawMessage
+	err := json.Unmarshal(b, &entries)
+	if err != nil {
+		return err
+	}
+
+	*n = BgpVrfNeighbors{}
+	for name, entry := range entries {
+		if len(entry) == 0 || entry[0] != '{' {
+			continue
+		}
+		neighbor := &BgpNeighbor{}
+		err = json.Unmarshal(entry, neighbor)
+		if err != nil {
+			return err
+		}
+		(*n)[name] = neighbor
+	}
+	return nil
+}
+
+// BgpNeighbor stores the details of a single peer
+type BgpNeighbor struct {
+	MessageStats      BgpMessageStats                  `json:"messageStats"`
+	AddressFamilyInfo map[string]*BgpAddressFamilyInfo `json:"addressFamilyInfo"`
+}
+
+// BgpMessageStats stores the number of messages exchanged with a peer by type
+type BgpMessageStats struct {
+	OpensSent         float64 `json:"opensSent"`
+	OpensRecv         float64 `json:"opensRecv"`
+	NotificationsSent float64 `json:"notificationsSent"`
+	NotificationsRecv float64 `json:"notificationsRecv"`
+	UpdatesSent       float64 `json:"updatesSent"`
+	UpdatesRecv       float64 `json:"updatesRecv"`
+	KeepalivesSent    float64 `json:"keepalivesSent"`
+	KeepalivesRecv    float64 `json:"keepalivesRecv"`
+	RouteRefreshSent  float64 `json:"routeRefreshSent"`
+	RouteRefreshRecv  float64 `json:"routeRefreshRecv"`
+	CapabilitySent    float64 `json:"capabilitySent"`
+	CapabilityRecv    float64 `json:"capabilityRecv"`
+}
+
+// BgpAddressFamilyInfo stores the per AFI/SAFI details of a peer
+type BgpAddressFamilyInfo struct {
+	AcceptedPrefixCounter float64 `json:"acceptedPrefixCounter"`
+}
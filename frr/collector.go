@@ -0,0 +1,191 @@
+package frr
+
+import (
+	"context"
+	"flag"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const prefix = "frr_bgp_"
+
+var (
+	vtyshPath = flag.String("collector.frr.vtysh-path", "/usr/bin/vtysh", "vtysh binary path")
+
+	peerInfoDesc                   *prometheus.Desc
+	peerEstablishedDesc            *prometheus.Desc
+	peerStateInfoDesc              *prometheus.Desc
+	peerUptimeDesc                 *prometheus.Desc
+	peerPrefixesReceivedDesc       *prometheus.Desc
+	peerPrefixesAcceptedDesc       *prometheus.Desc
+	peerPrefixesSentDesc           *prometheus.Desc
+	peerMessagesReceivedDesc       *prometheus.Desc
+	peerMessagesSentDesc           *prometheus.Desc
+	peerConnectionsEstablishedDesc *prometheus.Desc
+	peerConnectionsDroppedDesc     *prometheus.Desc
+)
+
+// Config configures the frr collector
+type Config struct {
+	collector.Settings `yaml:",inline"`
+	VtyshPath          string `yaml:"vtysh_path"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	if c.VtyshPath == "" {
+		return errors.New("vtysh_path must not be empty")
+	}
+	return nil
+}
+
+// Collector collects BGP metrics exposed by FRR's vtysh
+type Collector struct {
+	vtyshPath string
+}
+
+// NewCollector returns a new Collector instance
+func NewCollector(vtyshPath string) *Collector {
+	return &Collector{
+		vtyshPath: vtyshPath,
+	}
+}
+
+func init() {
+	collector.Register("frr", false, func() collector.Config {
+		return &Config{
+			VtyshPath: *vtyshPath,
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		return NewCollector(cfg.(*Config).VtyshPath), nil
+	})
+
+	peerLabels := []string{"vrf", "peer"}
+	afiSafiLabels := []string{"vrf", "afi_safi", "peer"}
+	peerInfoDesc = prometheus.NewDesc(prefix+"peer_info", "peer hostname, AS numbers and description", append(peerLabels, "hostname", "remote_as", "local_as", "description"), nil)
+	peerEstablishedDesc = prometheus.NewDesc(prefix+"peer_established_bool", "session established", afiSafiLabels, nil)
+	peerStateInfoDesc = prometheus.NewDesc(prefix+"peer_state_info", "session state", append(afiSafiLabels, "state"), nil)
+	peerUptimeDesc = prometheus.NewDesc(prefix+"peer_uptime_seconds", "time since the session was established", afiSafiLabels, nil)
+	peerPrefixesReceivedDesc = prometheus.NewDesc(prefix+"peer_prefixes_received", "prefixes received from the peer", afiSafiLabels, nil)
+	peerPrefixesAcceptedDesc = prometheus.NewDesc(prefix+"peer_prefixes_accepted", "prefixes received from the peer accepted by the inbound policy", afiSafiLabels, nil)
+	peerPrefixesSentDesc = prometheus.NewDesc(prefix+"peer_prefixes_sent", "prefixes sent to the peer", afiSafiLabels, nil)
+	peerMessagesReceivedDesc = prometheus.NewDesc(prefix+"peer_messages_received_total", "messages received from the peer", append(peerLabels, "type"), nil)
+	peerMessagesSentDesc = prometheus.NewDesc(prefix+"peer_messages_sent_total", "messages sent to the peer", append(peerLabels, "type"), nil)
+	peerConnectionsEstablishedDesc = prometheus.NewDesc(prefix+"peer_connections_established_total", "number of times the session was established", peerLabels, nil)
+	peerConnectionsDroppedDesc = prometheus.NewDesc(prefix+"peer_connections_dropped_total", "number of times the session was dropped", peerLabels, nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- peerInfoDesc
+	ch <- peerEstablishedDesc
+	ch <- peerStateInfoDesc
+	ch <- peerUptimeDesc
+	ch <- peerPrefixesReceivedDesc
+	ch <- peerPrefixesAcceptedDesc
+	ch <- peerPrefixesSentDesc
+	ch <- peerMessagesReceivedDesc
+	ch <- peerMessagesSentDesc
+	ch <- peerConnectionsEstablishedDesc
+	ch <- peerConnectionsDroppedDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	summary := BgpSummary{}
+	err := ShowJSON(ctx, c.vtyshPath, "show bgp vrf all summary json", &summary)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve BGP summary")
+		return
+	}
+
+	// the summary lacks accepted prefixes and per type message counters
+	neighbors := BgpNeighbors{}
+	err = ShowJSON(ctx, c.vtyshPath, "show bgp vrf all neighbors json", &neighbors)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve BGP neighbors")
+		return
+	}
+
+	for vrf, afiSafis := range summary {
+		// sessions are listed once per AFI/SAFI, per session metrics are
+		// only exported once
+		seenPeers := map[string]bool{}
+		for afiSafi, afiSafiSummary := range afiSafis {
+			if afiSafiSummary == nil {
+				continue
+			}
+			for peer, peerSummary := range afiSafiSummary.Peers {
+				neighbor := neighbors[vrf][peer]
+				collectForAfiSafi(vrf, afiSafi, peer, peerSummary, neighbor, metrics)
+				if seenPeers[peer] {
+					continue
+				}
+				seenPeers[peer] = true
+				collectForPeer(vrf, peer, peerSummary, neighbor, metrics)
+			}
+		}
+	}
+}
+
+// Name returns the string "FrrCollector"
+func (*Collector) Name() string {
+	return "FrrCollector"
+}
+
+func collectForAfiSafi(vrf string, afiSafi string, peer string, peerSummary *BgpPeerSummary, neighbor *BgpNeighbor, metrics chan<- prometheus.Metric) {
+	labels := []string{vrf, afiSafi, peer}
+	metrics <- prometheus.MustNewConstMetric(peerEstablishedDesc, prometheus.GaugeValue, util.BoolToFloat64(peerSummary.State == "Established"), labels...)
+	stateLabels := append(labels, peerSummary.State)
+	metrics <- prometheus.MustNewConstMetric(peerStateInfoDesc, prometheus.GaugeValue, 1.0, stateLabels...)
+	metrics <- prometheus.MustNewConstMetric(peerUptimeDesc, prometheus.GaugeValue, peerSummary.PeerUptimeMsec/1000, labels...)
+	metrics <- prometheus.MustNewConstMetric(peerPrefixesReceivedDesc, prometheus.GaugeValue, peerSummary.PfxRcd, labels...)
+	metrics <- prometheus.MustNewConstMetric(peerPrefixesSentDesc, prometheus.GaugeValue, peerSummary.PfxSnt, labels...)
+	if neighbor == nil {
+		return
+	}
+	if addressFamily, found := neighbor.AddressFamilyInfo[afiSafi]; found && addressFamily != nil {
+		metrics <- prometheus.MustNewConstMetric(peerPrefixesAcceptedDesc, prometheus.GaugeValue, addressFamily.AcceptedPrefixCounter, labels...)
+	}
+}
+
+func collectForPeer(vrf string, peer string, peerSummary *BgpPeerSummary, neighbor *BgpNeighbor, metrics chan<- prometheus.Metric) {
+	metrics <- prometheus.MustNewConstMetric(peerInfoDesc, prometheus.GaugeValue, 1.0, vrf, peer, peerSummary.Hostname, formatAS(peerSummary.RemoteAS), formatAS(peerSummary.LocalAS), peerSummary.Description)
+	metrics <- prometheus.MustNewConstMetric(peerConnectionsEstablishedDesc, prometheus.CounterValue, peerSummary.ConnectionsEstablished, vrf, peer)
+	metrics <- prometheus.MustNewConstMetric(peerConnectionsDroppedDesc, prometheus.CounterValue, peerSummary.ConnectionsDropped, vrf, peer)
+	if neighbor == nil {
+		return
+	}
+
+	stats := neighbor.MessageStats
+	received := map[string]float64{
+		"open":          stats.OpensRecv,
+		"notification":  stats.NotificationsRecv,
+		"update":        stats.UpdatesRecv,
+		"keepalive":     stats.KeepalivesRecv,
+		"route_refresh": stats.RouteRefreshRecv,
+		"capability":    stats.CapabilityRecv,
+	}
+	sent := map[string]float64{
+		"open":          stats.OpensSent,
+		"notification":  stats.NotificationsSent,
+		"update":        stats.UpdatesSent,
+		"keepalive":     stats.KeepalivesSent,
+		"route_refresh": stats.RouteRefreshSent,
+		"capability":    stats.CapabilitySent,
+	}
+	for messageType, value := range received {
+		metrics <- prometheus.MustNewConstMetric(peerMessagesReceivedDesc, prometheus.CounterValue, value, vrf, peer, messageType)
+	}
+	for messageType, value := range sent {
+		metrics <- prometheus.MustNewConstMetric(peerMessagesSentDesc, prometheus.CounterValue, value, vrf, peer, messageType)
+	}
+}
+
+func formatAS(as float64) string {
+	return strconv.FormatFloat(as, 'f', -1, 64)
+}
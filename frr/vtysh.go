@@ -0,0 +1,31 @@
+package frr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+)
+
+// ShowJSON executes "vtysh -c <command>" and unmarshals its output into v.
+// vtysh prints nothing for some commands if there is nothing to show, e.g. no
+// VNIs without EVPN configured, v is left unchanged then.
+func ShowJSON(ctx context.Context, vtyshPath string, command string, v interface{}) error {
+	stdout, stderr, err := sysroot.RunCommand(ctx, vtyshPath, "-c", command)
+	if err != nil {
+		return errors.Wrapf(err, "Executing '%s -c \"%s\"' failed, stderr reads: %s", vtyshPath, command, stderr)
+	}
+
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(stdout, v)
+	if err != nil {
+		return errors.Wrapf(err, "JSON unmarshal of '%s' failed", command)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package frr
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShowJSONEmptyOutput(t *testing.T) {
+	dir := t.TempDir()
+	err := flag.Set("sysroot", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer flag.Set("sysroot", "")
+
+	err = os.Mkdir(filepath.Join(dir, "commands"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "commands", "vtysh_-c_show_evpn_vni_detail_json"), []byte("\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := map[string]interface{}{}
+	err = ShowJSON(context.Background(), "/usr/bin/vtysh", "show evpn vni detail json", &v)
+	if err != nil {
+		t.Fatalf("empty output failed: %v", err)
+	}
+	if len(v) != 0 {
+		t.Errorf("got %v from empty output", v)
+	}
+}
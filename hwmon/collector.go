@@ -1,8 +1,12 @@
 package hwmon
 
 import (
+	"context"
+
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
-	"os/exec"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
 )
 
 const prefix = "hwmon_"
@@ -48,6 +52,10 @@ var (
 )
 
 func init() {
+	collector.Register("hwmon", false, nil, func(collector.Config) (collector.Collector, error) {
+		return NewCollector(), nil
+	})
+
 	// we're using these labels for legacy reasons, in order to not
 	// break existing dashboards. these names do not reflect the
 	// description of the value. FIXME copy into other labels & deprecate(?)
@@ -143,12 +151,8 @@ func (*Collector) Name() string {
 	return "HwmonCollector"
 }
 
-func (c *Collector) Collect(metrics chan<- prometheus.Metric, errorChan chan error, done chan struct{}) {
-	defer func() {
-		done <- struct{}{}
-	}()
-
-	smonCtlOut, err := runSmonCtl()
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	smonCtlOut, err := runSmonCtl(ctx)
 	if err != nil {
 		errorChan <- err
 		return
@@ -157,18 +161,16 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric, errorChan chan err
 	collectSensors(smonCtlOut, metrics, errorChan)
 }
 
-func runSmonCtl() ([]byte, error) {
-	cmd := exec.Command("smonctl", "--json", "-v")
-
-	out, err := cmd.CombinedOutput()
+func runSmonCtl(ctx context.Context) ([]byte, error) {
+	stdout, stderr, err := sysroot.RunCommand(ctx, "smonctl", "--json", "-v")
 	if err != nil {
-		return nil, err	
+		return nil, errors.Wrapf(err, "Executing 'smonctl --json -v' failed, stderr reads: %s", stderr)
 	}
 
-	return out, nil
+	return stdout, nil
 }
 
-func collectSensors(data []byte, metrics chan<- prometheus.Metric, errorChan chan error) {
+func collectSensors(data []byte, metrics chan<- prometheus.Metric, errorChan chan<- error) {
 	sensors, err := UnmarshalSensors(data)
 	if err != nil {
 		errorChan <- err
@@ -0,0 +1,125 @@
+package interfaces
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const prefix = "cumulus_interface_"
+
+var (
+	interfaceFilter = util.InterfaceFilterFlags("interfaces")
+
+	// breakout ports are named after the port they are split from, e.g. swp1s2
+	breakoutRegex = regexp.MustCompile(`^(swp\d+)s\d+$`)
+
+	infoDesc *prometheus.Desc
+)
+
+// Config configures the interfaces collector
+type Config struct {
+	collector.Settings   `yaml:",inline"`
+	util.InterfaceFilter `yaml:",inline"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	return c.InterfaceFilter.Validate()
+}
+
+// Collector exposes the alias, master and bridge of every link, to be joined
+// onto the metrics of other collectors
+type Collector struct {
+	interfaces *util.InterfaceMatcher
+}
+
+// NewCollector returns a new Collector instance
+func NewCollector(interfaces *util.InterfaceMatcher) *Collector {
+	return &Collector{
+		interfaces: interfaces,
+	}
+}
+
+func init() {
+	collector.Register("interfaces", false, func() collector.Config {
+		return &Config{
+			InterfaceFilter: interfaceFilter(),
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		interfaces, err := cfg.(*Config).Matcher()
+		if err != nil {
+			return nil, err
+		}
+		return NewCollector(interfaces), nil
+	})
+
+	infoDesc = prometheus.NewDesc(prefix+"info", "Link information, bridge is the VLAN-aware bridge the link is a member of directly or through its bond", []string{"interface", "alias", "kind", "master", "bridge", "breakout_parent"}, nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- infoDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	links, err := sysroot.LinkList()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not get link list")
+		return
+	}
+
+	linksByIndex := map[int]netlink.Link{}
+	for _, link := range links {
+		linksByIndex[link.Attrs().Index] = link
+	}
+
+	for _, link := range links {
+		attrs := link.Attrs()
+		if !c.interfaces.Matches(attrs.Name) {
+			continue
+		}
+
+		master := ""
+		if masterLink, found := linksByIndex[attrs.MasterIndex]; found {
+			master = masterLink.Attrs().Name
+		}
+		breakoutParent := ""
+		if match := breakoutRegex.FindStringSubmatch(attrs.Name); match != nil {
+			breakoutParent = match[1]
+		}
+		metrics <- prometheus.MustNewConstMetric(infoDesc, prometheus.GaugeValue, 1, attrs.Name, attrs.Alias, link.Type(), master, vlanAwareBridge(link, linksByIndex), breakoutParent)
+	}
+}
+
+// vlanAwareBridge returns the name of the VLAN-aware bridge link is enslaved
+// to, either directly or through a bond
+func vlanAwareBridge(link netlink.Link, linksByIndex map[int]netlink.Link) string {
+	master, found := linksByIndex[link.Attrs().MasterIndex]
+	if !found {
+		return ""
+	}
+	if _, isBond := master.(*netlink.Bond); isBond {
+		master, found = linksByIndex[master.Attrs().MasterIndex]
+		if !found {
+			return ""
+		}
+	}
+	bridge, isBridge := master.(*netlink.Bridge)
+	if !isBridge || bridge.VlanFiltering == nil || !*bridge.VlanFiltering {
+		return ""
+	}
+	return bridge.Name
+}
+
+// Name returns the string "InterfacesCollector"
+func (*Collector) Name() string {
+	return "InterfacesCollector"
+}
@@ -0,0 +1,206 @@
+package linkstate
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vishvananda/netlink"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const prefix = "linkstate_"
+
+var (
+	interfaceFilter = util.InterfaceFilterFlags("linkstate")
+
+	carrierDesc         *prometheus.Desc
+	carrierChangesDesc  *prometheus.Desc
+	flapsDesc           *prometheus.Desc
+	lastChangeDesc      *prometheus.Desc
+	speedDesc           *prometheus.Desc
+	duplexInfoDesc      *prometheus.Desc
+	mtuDesc             *prometheus.Desc
+	operUpDesc          *prometheus.Desc
+	operStateInfoDesc   *prometheus.Desc
+	protoDownDesc       *prometheus.Desc
+	protoDownReasonDesc *prometheus.Desc
+)
+
+// Config configures the linkstate collector
+type Config struct {
+	collector.Settings   `yaml:",inline"`
+	util.InterfaceFilter `yaml:",inline"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	return c.InterfaceFilter.Validate()
+}
+
+// Collector collects the state of links from sysfs and netlink. Carrier flaps
+// are counted from netlink link updates in the background.
+type Collector struct {
+	interfaces *util.InterfaceMatcher
+	flaps      *flapTracker
+}
+
+// NewCollector returns a new Collector instance. Unless running against a
+// sysroot, it subscribes to link updates until it is closed.
+func NewCollector(interfaces *util.InterfaceMatcher) *Collector {
+	c := &Collector{
+		interfaces: interfaces,
+	}
+	if !sysroot.Enabled() {
+		c.flaps = newFlapTracker()
+	}
+	return c
+}
+
+func init() {
+	collector.Register("linkstate", false, func() collector.Config {
+		return &Config{
+			InterfaceFilter: interfaceFilter(),
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		interfaces, err := cfg.(*Config).Matcher()
+		if err != nil {
+			return nil, err
+		}
+		return NewCollector(interfaces), nil
+	})
+
+	labels := []string{"interface"}
+	carrierDesc = prometheus.NewDesc(prefix+"carrier_bool", "1 if the link has carrier", labels, nil)
+	carrierChangesDesc = prometheus.NewDesc(prefix+"carrier_changes_total", "Number of carrier changes as counted by the kernel", labels, nil)
+	flapsDesc = prometheus.NewDesc(prefix+"flaps_total", "Number of times the link lost carrier since the exporter started", labels, nil)
+	lastChangeDesc = prometheus.NewDesc(prefix+"last_change_timestamp_seconds", "Time of the last carrier change seen since the exporter started", labels, nil)
+	speedDesc = prometheus.NewDesc(prefix+"speed_bytes", "Negotiated speed of the link in bytes per second", labels, nil)
+	duplexInfoDesc = prometheus.NewDesc(prefix+"duplex_info", "Duplex mode of the link", append(labels, "duplex"), nil)
+	mtuDesc = prometheus.NewDesc(prefix+"mtu_bytes", "MTU of the link", labels, nil)
+	operUpDesc = prometheus.NewDesc(prefix+"oper_up_bool", "1 if the operational state of the link is up", labels, nil)
+	operStateInfoDesc = prometheus.NewDesc(prefix+"oper_state_info", "Operational state of the link", append(labels, "state"), nil)
+	protoDownDesc = prometheus.NewDesc(prefix+"proto_down_bool", "1 if the link is protodown", labels, nil)
+	protoDownReasonDesc = prometheus.NewDesc(prefix+"proto_down_reason_info", "Reason the link is protodown for", append(labels, "reason"), nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- carrierDesc
+	ch <- carrierChangesDesc
+	ch <- flapsDesc
+	ch <- lastChangeDesc
+	ch <- speedDesc
+	ch <- duplexInfoDesc
+	ch <- mtuDesc
+	ch <- operUpDesc
+	ch <- operStateInfoDesc
+	ch <- protoDownDesc
+	ch <- protoDownReasonDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	links, err := sysroot.LinkList()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not get link list")
+		return
+	}
+	protoDownReasons, err := sysroot.ProtoDownReasons()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not get protodown reasons")
+	}
+	var flaps map[string]linkFlaps
+	if c.flaps != nil {
+		c.flaps.prune(links)
+		flaps = c.flaps.get()
+	}
+
+	for _, link := range links {
+		attrs := link.Attrs()
+		if !c.interfaces.Matches(attrs.Name) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		labels := []string{attrs.Name}
+		metrics <- prometheus.MustNewConstMetric(mtuDesc, prometheus.GaugeValue, float64(attrs.MTU), labels...)
+		metrics <- prometheus.MustNewConstMetric(operUpDesc, prometheus.GaugeValue, util.BoolToFloat64(attrs.OperState == netlink.OperUp), labels...)
+		metrics <- prometheus.MustNewConstMetric(operStateInfoDesc, prometheus.GaugeValue, 1, append(labels, attrs.OperState.String())...)
+		for _, reason := range protoDownReasons[attrs.Name] {
+			metrics <- prometheus.MustNewConstMetric(protoDownReasonDesc, prometheus.GaugeValue, 1, append(labels, reason)...)
+		}
+
+		if history, found := flaps[attrs.Name]; found {
+			metrics <- prometheus.MustNewConstMetric(flapsDesc, prometheus.CounterValue, float64(history.flaps), labels...)
+			if !history.lastChange.IsZero() {
+				metrics <- prometheus.MustNewConstMetric(lastChangeDesc, prometheus.GaugeValue, float64(history.lastChange.UnixNano())/1e9, labels...)
+			}
+		}
+
+		err = c.collectSysfs(attrs.Name, labels, metrics)
+		if err != nil {
+			errorChan <- err
+		}
+	}
+}
+
+// collectSysfs exposes the link attributes the netlink library does not parse
+func (c *Collector) collectSysfs(interfaceName string, labels []string, metrics chan<- prometheus.Metric) error {
+	carrier, err := readIntAttribute(interfaceName, "carrier")
+	if err == errNotAvailable {
+		// the kernel only reports carrier for links that are admin up
+		carrier, err = 0, nil
+	}
+	if err != nil {
+		return err
+	}
+	metrics <- prometheus.MustNewConstMetric(carrierDesc, prometheus.GaugeValue, float64(carrier), labels...)
+
+	carrierChanges, err := readIntAttribute(interfaceName, "carrier_changes")
+	if err != nil {
+		return err
+	}
+	metrics <- prometheus.MustNewConstMetric(carrierChangesDesc, prometheus.CounterValue, float64(carrierChanges), labels...)
+
+	protoDown, err := readIntAttribute(interfaceName, "proto_down")
+	if err != nil {
+		return err
+	}
+	metrics <- prometheus.MustNewConstMetric(protoDownDesc, prometheus.GaugeValue, float64(protoDown), labels...)
+
+	// speed and duplex are only known while the link is up, and not at all
+	// for virtual links, which may lack them in recorded data
+	speed, err := readIntAttribute(interfaceName, "speed")
+	if err != nil && !isOptional(err) {
+		return err
+	}
+	if err == nil && speed > 0 {
+		metrics <- prometheus.MustNewConstMetric(speedDesc, prometheus.GaugeValue, float64(speed)*1000*1000/8, labels...)
+	}
+
+	duplex, err := readAttribute(interfaceName, "duplex")
+	if err != nil && !isOptional(err) {
+		return err
+	}
+	if err == nil && duplex != "unknown" {
+		metrics <- prometheus.MustNewConstMetric(duplexInfoDesc, prometheus.GaugeValue, 1, append(labels, duplex)...)
+	}
+	return nil
+}
+
+// Close implements collector.Closer by ending the link update subscription
+func (c *Collector) Close() {
+	if c.flaps != nil {
+		c.flaps.stop()
+	}
+}
+
+// Name returns the string "LinkstateCollector"
+func (*Collector) Name() string {
+	return "LinkstateCollector"
+}
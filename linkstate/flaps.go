@@ -0,0 +1,181 @@
+package linkstate
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// resubscribeDelay is the time waited before subscribing again after the
+// subscription failed, e.g. because the socket's receive buffer overran
+const resubscribeDelay = 5 * time.Second
+
+// linkFlaps is the carrier history of a single link
+type linkFlaps struct {
+	name    string
+	carrier bool
+	// flaps counts the transitions from carrier up to down
+	flaps      uint64
+	lastChange time.Time
+}
+
+// flapTracker follows the netlink link updates to count carrier flaps, so
+// flaps happening between two scrapes are not missed
+type flapTracker struct {
+	lock  sync.Mutex
+	links map[int]*linkFlaps
+	done  chan struct{}
+}
+
+func newFlapTracker() *flapTracker {
+	t := &flapTracker{
+		links: map[int]*linkFlaps{},
+		done:  make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// stop ends the subscription
+func (t *flapTracker) stop() {
+	close(t.done)
+}
+
+func (t *flapTracker) run() {
+	for {
+		err := t.subscribe()
+		if err != nil {
+			log.Errorf("Could not subscribe to link updates: %v", err)
+		}
+
+		select {
+		case <-t.done:
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+// subscribe handles link updates until the subscription fails or the tracker
+// is stopped. Existing links are listed first, so transitions missed while not
+// subscribed are picked up from the changed state.
+func (t *flapTracker) subscribe() error {
+	updates := make(chan netlink.LinkUpdate)
+	subscriptionDone := make(chan struct{})
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-t.done:
+		case <-finished:
+		}
+		close(subscriptionDone)
+	}()
+
+	err := netlink.LinkSubscribeWithOptions(updates, subscriptionDone, netlink.LinkSubscribeOptions{
+		ListExisting: true,
+		ErrorCallback: func(err error) {
+			select {
+			case <-subscriptionDone:
+			default:
+				log.Warnf("Link update subscription: %v", err)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for update := range updates {
+		t.handle(update)
+	}
+	return nil
+}
+
+func (t *flapTracker) handle(update netlink.LinkUpdate) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	accountEvent(t.links, newLinkEvent(update), time.Now())
+}
+
+// linkEvent is the part of a link update the flap accounting is based on
+type linkEvent struct {
+	index   int
+	name    string
+	carrier bool
+	deleted bool
+}
+
+func newLinkEvent(update netlink.LinkUpdate) linkEvent {
+	event := linkEvent{
+		index:   int(update.Index),
+		carrier: update.Flags&unix.IFF_LOWER_UP != 0,
+		deleted: update.Header.Type == unix.RTM_DELLINK,
+	}
+	if update.Link != nil {
+		event.name = update.Link.Attrs().Name
+	}
+	return event
+}
+
+// accountEvent applies event, seen at now, to the carrier history of links.
+// A link seen for the first time starts without flaps, a deleted link is
+// forgotten.
+func accountEvent(links map[int]*linkFlaps, event linkEvent, now time.Time) {
+	if event.deleted {
+		delete(links, event.index)
+		return
+	}
+
+	link, found := links[event.index]
+	if !found {
+		links[event.index] = &linkFlaps{
+			name:    event.name,
+			carrier: event.carrier,
+		}
+		return
+	}
+
+	link.name = event.name
+	if link.carrier == event.carrier {
+		return
+	}
+	if !event.carrier {
+		link.flaps++
+	}
+	link.carrier = event.carrier
+	link.lastChange = now
+}
+
+// get returns a copy of the history of all links keyed by name
+func (t *flapTracker) get() map[string]linkFlaps {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	res := make(map[string]linkFlaps, len(t.links))
+	for _, link := range t.links {
+		res[link.name] = *link
+	}
+	return res
+}
+
+// prune forgets the links not in links. Deletions are normally seen as
+// RTM_DELLINK, but may be missed while not subscribed.
+func (t *flapTracker) prune(links []netlink.Link) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	present := make(map[int]bool, len(links))
+	for _, link := range links {
+		present[link.Attrs().Index] = true
+	}
+	for index := range t.links {
+		if !present[index] {
+			delete(t.links, index)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package linkstate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func up(index int, name string) linkEvent {
+	return linkEvent{index: index, name: name, carrier: true}
+}
+
+func down(index int, name string) linkEvent {
+	return linkEvent{index: index, name: name}
+}
+
+func deleted(index int) linkEvent {
+	return linkEvent{index: index, deleted: true}
+}
+
+func TestAccountEvent(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		events   []linkEvent
+		expected map[int]linkFlaps
+	}{
+		{
+			name:     "first seen down",
+			events:   []linkEvent{down(3, "swp1")},
+			expected: map[int]linkFlaps{3: {name: "swp1"}},
+		},
+		{
+			name:     "first seen up",
+			events:   []linkEvent{up(3, "swp1"), up(3, "swp1")},
+			expected: map[int]linkFlaps{3: {name: "swp1", carrier: true}},
+		},
+		{
+			name:   "flaps",
+			events: []linkEvent{up(3, "swp1"), down(3, "swp1"), down(3, "swp1"), up(3, "swp1"), down(3, "swp1"), up(3, "swp1")},
+			expected: map[int]linkFlaps{
+				3: {name: "swp1", carrier: true, flaps: 2, lastChange: start.Add(5 * time.Second)},
+			},
+		},
+		{
+			name:   "carrier coming up is no flap",
+			events: []linkEvent{down(3, "swp1"), up(3, "swp1")},
+			expected: map[int]linkFlaps{
+				3: {name: "swp1", carrier: true, lastChange: start.Add(time.Second)},
+			},
+		},
+		{
+			name:   "renamed",
+			events: []linkEvent{up(3, "swp1"), down(3, "swp1s0")},
+			expected: map[int]linkFlaps{
+				3: {name: "swp1s0", flaps: 1, lastChange: start.Add(time.Second)},
+			},
+		},
+		{
+			name:   "deleted and created again",
+			events: []linkEvent{up(3, "swp1"), down(3, "swp1"), up(4, "swp2"), deleted(3), down(3, "swp1")},
+			expected: map[int]linkFlaps{
+				3: {name: "swp1"},
+				4: {name: "swp2", carrier: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			links := map[int]*linkFlaps{}
+			for i, event := range test.events {
+				accountEvent(links, event, start.Add(time.Duration(i)*time.Second))
+			}
+			got := map[int]linkFlaps{}
+			for index, link := range links {
+				got[index] = *link
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("got %+v, want %+v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewLinkEvent(t *testing.T) {
+	link := &netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: 3, Name: "swp1"}}
+	update := netlink.LinkUpdate{
+		IfInfomsg: nl.IfInfomsg{IfInfomsg: unix.IfInfomsg{Index: 3, Flags: unix.IFF_UP | unix.IFF_LOWER_UP}},
+		Header:    unix.NlMsghdr{Type: unix.RTM_NEWLINK},
+		Link:      link,
+	}
+	event := newLinkEvent(update)
+	if event != up(3, "swp1") {
+		t.Errorf("got %+v for a link with carrier", event)
+	}
+
+	update.Flags = unix.IFF_UP
+	event = newLinkEvent(update)
+	if event != down(3, "swp1") {
+		t.Errorf("got %+v for a link without carrier", event)
+	}
+
+	update.Header.Type = unix.RTM_DELLINK
+	event = newLinkEvent(update)
+	if !event.deleted || event.index != 3 {
+		t.Errorf("got %+v for a deleted link", event)
+	}
+}
+
+func TestFlapTrackerPrune(t *testing.T) {
+	tracker := &flapTracker{links: map[int]*linkFlaps{}}
+	for _, event := range []linkEvent{up(3, "swp1"), up(4, "swp2"), down(3, "swp1")} {
+		accountEvent(tracker.links, event, time.Now())
+	}
+
+	tracker.prune([]netlink.Link{&netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: 4, Name: "swp2"}}})
+	history := tracker.get()
+	if _, found := history["swp1"]; found {
+		t.Error("missing link is still tracked")
+	}
+	if _, found := history["swp2"]; !found {
+		t.Error("present link is no longer tracked")
+	}
+
+	// the deletion was missed, the link created again starts without flaps
+	tracker.handle(netlink.LinkUpdate{
+		IfInfomsg: nl.IfInfomsg{IfInfomsg: unix.IfInfomsg{Index: 3}},
+		Header:    unix.NlMsghdr{Type: unix.RTM_NEWLINK},
+		Link:      &netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: 3, Name: "swp1"}},
+	})
+	if flaps := tracker.get()["swp1"].flaps; flaps != 0 {
+		t.Errorf("got %d flaps of the link created again, want 0", flaps)
+	}
+}
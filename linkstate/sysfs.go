@@ -0,0 +1,45 @@
+package linkstate
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+)
+
+// errNotAvailable is returned for attributes the kernel does not report in
+// the link's current state, e.g. the speed of a link that is down
+var errNotAvailable = errors.New("not available")
+
+func readAttribute(interfaceName string, attribute string) (string, error) {
+	path := sysroot.Path(filepath.Join("/sys/class/net", interfaceName, attribute))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, syscall.EINVAL) {
+			return "", errNotAvailable
+		}
+		return "", errors.Wrapf(err, "Could not read '%s'", path)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func readIntAttribute(interfaceName string, attribute string) (int64, error) {
+	value, err := readAttribute(interfaceName, attribute)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Could not parse %s of %s", attribute, interfaceName)
+	}
+	return res, nil
+}
+
+// isOptional returns true if err only signals that an attribute is missing
+func isOptional(err error) bool {
+	return err == errNotAvailable || errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// listenAddresses is a flag that may be given multiple times
+type listenAddresses []string
+
+func (l *listenAddresses) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listenAddresses) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// splitListenAddress splits an address of the form "host:port[@device]"
+func splitListenAddress(address string) (string, string) {
+	index := strings.LastIndex(address, "@")
+	if index == -1 {
+		return address, ""
+	}
+	return address[:index], address[index+1:]
+}
+
+// listen opens a TCP listener for address. If address names a device (or VRF,
+// which is a device on Linux), the socket is bound to it via SO_BINDTODEVICE.
+func listen(address string) (net.Listener, error) {
+	hostPort, device := splitListenAddress(address)
+	if device == "" {
+		return net.Listen("tcp", hostPort)
+	}
+
+	listenConfig := net.ListenConfig{
+		Control: func(network, address string, conn syscall.RawConn) error {
+			var bindErr error
+			err := conn.Control(func(fd uintptr) {
+				bindErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, device)
+			})
+			if err != nil {
+				return err
+			}
+			return errors.Wrapf(bindErr, "Could not bind socket to device %s", device)
+		},
+	}
+	return listenConfig.Listen(context.Background(), "tcp", hostPort)
+}
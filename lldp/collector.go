@@ -0,0 +1,107 @@
+package lldp
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const prefix = "cumulus_lldp_"
+
+var (
+	lldpctlPath     = flag.String("collector.lldp.lldpctl-path", "/usr/sbin/lldpctl", "lldpctl binary path")
+	interfaceFilter = util.InterfaceFilterFlags("lldp")
+
+	neighborInfoDesc *prometheus.Desc
+	neighborAgeDesc  *prometheus.Desc
+)
+
+// Config configures the lldp collector
+type Config struct {
+	collector.Settings   `yaml:",inline"`
+	util.InterfaceFilter `yaml:",inline"`
+	LldpctlPath          string `yaml:"lldpctl_path"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	if c.LldpctlPath == "" {
+		return errors.New("lldpctl_path must not be empty")
+	}
+	return c.InterfaceFilter.Validate()
+}
+
+// Collector collects LLDP neighbors exposed by lldpctl
+type Collector struct {
+	lldpctlPath string
+	interfaces  *util.InterfaceMatcher
+}
+
+// NewCollector returns a new Collector instance
+func NewCollector(lldpctlPath string, interfaces *util.InterfaceMatcher) *Collector {
+	return &Collector{
+		lldpctlPath: lldpctlPath,
+		interfaces:  interfaces,
+	}
+}
+
+func init() {
+	collector.Register("lldp", false, func() collector.Config {
+		return &Config{
+			InterfaceFilter: interfaceFilter(),
+			LldpctlPath:     *lldpctlPath,
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		config := cfg.(*Config)
+		interfaces, err := config.Matcher()
+		if err != nil {
+			return nil, err
+		}
+		return NewCollector(config.LldpctlPath, interfaces), nil
+	})
+
+	labels := []string{"interface", "chassis_id", "port_id"}
+	neighborInfoDesc = prometheus.NewDesc(prefix+"neighbor_info", "neighbor seen on the interface", append(labels, "chassis_name", "port_description", "management_address"), nil)
+	neighborAgeDesc = prometheus.NewDesc(prefix+"neighbor_age_seconds", "time since the neighbor was discovered", labels, nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- neighborInfoDesc
+	ch <- neighborAgeDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	result, err := ShowNeighbors(ctx, c.lldpctlPath)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve LLDP neighbors")
+		return
+	}
+
+	for _, iface := range result.Lldp.Interfaces {
+		if !c.interfaces.Matches(iface.Name) {
+			continue
+		}
+		collectForInterface(iface, metrics)
+	}
+}
+
+// Name returns the string "LldpCollector"
+func (*Collector) Name() string {
+	return "LldpCollector"
+}
+
+func collectForInterface(iface *Interface, metrics chan<- prometheus.Metric) {
+	labels := []string{iface.Name, iface.Chassis.ID.Value, iface.Port.ID.Value}
+	infoLabels := append(labels, iface.Chassis.Name, iface.Port.Description, strings.Join(iface.Chassis.MgmtAddresses, ","))
+	metrics <- prometheus.MustNewConstMetric(neighborInfoDesc, prometheus.GaugeValue, 1.0, infoLabels...)
+	if age, ok := iface.AgeSeconds(); ok {
+		metrics <- prometheus.MustNewConstMetric(neighborAgeDesc, prometheus.GaugeValue, age, labels...)
+	}
+}
@@ -0,0 +1,154 @@
+package lldp
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+)
+
+var agePattern = regexp.MustCompile(`^(\d+) days?, (\d+):(\d+):(\d+)$`)
+
+// LldpctlResult stores the parsed output of `lldpctl -f json`
+type LldpctlResult struct {
+	Lldp struct {
+		Interfaces Interfaces `json:"interface"`
+	} `json:"lldp"`
+}
+
+// Interface stores a neighbor seen on a local interface
+type Interface struct {
+	Name    string
+	Age     string  `json:"age"`
+	Chassis Chassis `json:"chassis"`
+	Port    Port    `json:"port"`
+}
+
+// Interfaces is the list of local interfaces having a neighbor. lldpctl
+// prints an object indexed by interface name if there is a single neighbor,
+// and a list of such objects otherwise.
+type Interfaces []*Interface
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *Interfaces) UnmarshalJSON(b []byte) error {
+	var objects []map[string]*Interface
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		err := json.Unmarshal(b, &objects)
+		if err != nil {
+			return err
+		}
+	} else {
+		var object map[string]*Interface
+		err := json.Unmarshal(b, &object)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, object)
+	}
+
+	*l = Interfaces{}
+	for _, object := range objects {
+		for name, iface := range object {
+			if iface == nil {
+				continue
+			}
+			iface.Name = name
+			*l = append(*l, iface)
+		}
+	}
+	return nil
+}
+
+// AgeSeconds returns the time since the neighbor was discovered, lldpctl
+// formats it as "<n> day(s), hh:mm:ss"
+func (i *Interface) AgeSeconds() (float64, bool) {
+	match := agePattern.FindStringSubmatch(i.Age)
+	if match == nil {
+		return 0, false
+	}
+	age := 0.0
+	for index, factor := range []float64{86400, 3600, 60, 1} {
+		value, err := strconv.ParseFloat(match[index+1], 64)
+		if err != nil {
+			return 0, false
+		}
+		age += value * factor
+	}
+	return age, true
+}
+
+// Chassis stores the remote chassis. lldpctl indexes it by the chassis name,
+// chassis without a name are printed directly.
+type Chassis struct {
+	Name          string
+	ID            Value
+	MgmtAddresses StringList
+}
+
+type chassisDetails struct {
+	ID     Value      `json:"id"`
+	MgmtIP StringList `json:"mgmt-ip"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *Chassis) UnmarshalJSON(b []byte) error {
+	var entries map[string]json.RawMessage
+	err := json.Unmarshal(b, &entries)
+	if err != nil {
+		return err
+	}
+
+	details := chassisDetails{}
+	if _, found := entries["id"]; found {
+		err = json.Unmarshal(b, &details)
+		if err != nil {
+			return err
+		}
+	} else {
+		for name, entry := range entries {
+			c.Name = name
+			err = json.Unmarshal(entry, &details)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	c.ID = details.ID
+	c.MgmtAddresses = details.MgmtIP
+	return nil
+}
+
+// Port stores the remote port
+type Port struct {
+	ID          Value  `json:"id"`
+	Description string `json:"descr"`
+}
+
+// Value is a typed identifier, e.g. a MAC address or interface name
+type Value struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// StringList is a list of strings, that lldpctl prints as a single string if
+// it has one element
+type StringList []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *StringList) UnmarshalJSON(b []byte) error {
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		*l = list
+		return nil
+	}
+
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	*l = StringList{s}
+	return nil
+}
@@ -0,0 +1,25 @@
+package lldp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+)
+
+// ShowNeighbors executes and parses "lldpctl -f json"
+func ShowNeighbors(ctx context.Context, lldpctlPath string) (*LldpctlResult, error) {
+	stdout, stderr, err := sysroot.RunCommand(ctx, lldpctlPath, "-f", "json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Executing '%s -f json' failed, stderr reads: %s", lldpctlPath, stderr)
+	}
+
+	res := &LldpctlResult{}
+	err = json.Unmarshal(stdout, res)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON unmarshal failed")
+	}
+
+	return res, nil
+}
@@ -1,43 +1,64 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
-	"regexp"
-	"strings"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/wobcom/transceiver-exporter/transceiver-collector"
-	"gitlab.com/wobcom/cumulus-exporter/asic"
 	"gitlab.com/wobcom/cumulus-exporter/collector"
-	"gitlab.com/wobcom/cumulus-exporter/hwmon"
-	"gitlab.com/wobcom/cumulus-exporter/mstpd"
+	"gitlab.com/wobcom/cumulus-exporter/config"
+	"gitlab.com/wobcom/cumulus-exporter/events"
 
+	// collectors register themselves with the collector package
+	_ "gitlab.com/wobcom/cumulus-exporter/asic"
+	_ "gitlab.com/wobcom/cumulus-exporter/clagd"
+	_ "gitlab.com/wobcom/cumulus-exporter/devlink"
+	_ "gitlab.com/wobcom/cumulus-exporter/evpn"
+	_ "gitlab.com/wobcom/cumulus-exporter/frr"
+	_ "gitlab.com/wobcom/cumulus-exporter/hwmon"
+	_ "gitlab.com/wobcom/cumulus-exporter/interfaces"
+	_ "gitlab.com/wobcom/cumulus-exporter/linkstate"
+	_ "gitlab.com/wobcom/cumulus-exporter/lldp"
+	_ "gitlab.com/wobcom/cumulus-exporter/mstpd"
+	_ "gitlab.com/wobcom/cumulus-exporter/portstats"
+	_ "gitlab.com/wobcom/cumulus-exporter/ptm"
+	_ "gitlab.com/wobcom/cumulus-exporter/transceiver"
+
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/exporter-toolkit/web"
 )
 
 const version string = "1.1.0"
 
 var (
-	showVersion              = flag.Bool("version", false, "Print version and exit")
-	listenAddress            = flag.String("web.listen-address", "[::]:9457", "Address to listen on")
-	metricsPath              = flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics")
-	asicCollector            = flag.Bool("collectors.asic", false, "Enable ASIC collector")
-	transceiverCollector     = flag.Bool("collectors.transceiver", false, "Enable transceiver collector (rx / tx power, temperatures, etc.)")
-	collectInterfaceFeatures = flag.Bool("collectors.transceiver.interface-features", false, "Collect interface features (results in many time series)")
-	excludeInterfaces        = flag.String("collectors.transceiver.exclude-interfaces", "", "Comma seperated list of interfaces to exclude from scrape")
-	includeInterfaces        = flag.String("collectors.transceiver.include-interfaces", "", "Comma seperated list of interfaces to include from scrape")
-	excludeInterfacesRegex   = flag.String("collectors.transceiver.exclude-interfaces-regex", "", "Regex Expression for interfaces to exclude from scrape")
-	includeInterfacesRegex   = flag.String("collectors.transceiver.include-interfaces-regex", "", "Regex Expression for interfaces to include from scrape")
-	hwmonCollector           = flag.Bool("collectors.hwmon", false, "Enable hwmon collector")
-	mstpdCollector           = flag.Bool("collectors.mstpd", false, "Enable mstpd collector")
-	mstpctlPath              = flag.String("collectors.mstpd.mstpctl-path", "/sbin/mstpctl", "mstpctl binary path")
-	logLevel                 = flag.String("log.level", "info", "The level the application logs at")
-	enabledCollectors        []collector.Collector
+	showVersion       = flag.Bool("version", false, "Print version and exit")
+	metricsPath       = flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics")
+	webConfigFile     = flag.String("web.config.file", "", "Path to a web configuration file enabling TLS and / or basic authentication, in the exporter-toolkit format")
+	collectorTimeout  = flag.Duration("collectors.timeout", 10*time.Second, "Time after which a collector is abandoned and its metrics are discarded")
+	collectorInterval = flag.Duration("collectors.interval", 0, "Run collectors in the background at this interval and serve scrapes from their last result (0 collects on every scrape)")
+	aliasLabel        = flag.Bool("collectors.interface-alias-label", false, "Add the link alias as alias label to all series having an interface label")
+	logLevel          = flag.String("log.level", "info", "The level the application logs at")
+	maxRequests       = flag.Int("web.max-requests", 40, "Maximum number of concurrent scrape requests, further requests are answered with 503 (0 means no limit)")
+	configFile        = flag.String("config.file", "", "YAML configuration file, overrides the collector flags and is reloaded on SIGHUP")
+
+	listenAddress         listenAddresses
+	enabledCollectors     []*enabledCollector
+	interfaceAliasLabel   bool
+	generation            uint64
+	eventManager          *events.Manager
+	enabledCollectorsLock = &sync.RWMutex{}
+	scrapeSlots           chan struct{}
 )
 
 func printVersion() {
@@ -56,8 +77,15 @@ func setLogLevel() {
 	log.SetLevel(level)
 }
 
+func init() {
+	flag.Var(&listenAddress, "web.listen-address", "Address to listen on, may be repeated. Append @<vrf> or @<device> to bind to a VRF or device, e.g. [::]:9457@mgmt (default [::]:9457)")
+}
+
 func main() {
 	flag.Parse()
+	if len(listenAddress) == 0 {
+		listenAddress = listenAddresses{"[::]:9457"}
+	}
 	setLogLevel()
 
 	if *showVersion {
@@ -67,63 +95,142 @@ func main() {
 	startServer()
 }
 
-func initialize() {
-	if *asicCollector {
-		log.Info("asic collector enabled")
-		enabledCollectors = append(enabledCollectors, asic.NewCollector())
+func loadConfig() (*config.Config, error) {
+	cfg := config.FromFlags(*collectorTimeout, *collectorInterval, *aliasLabel)
+	if *configFile == "" {
+		return cfg, cfg.Validate()
 	}
-	if *transceiverCollector {
-		log.Info("transceiver collector enabled")
+	return config.Load(*configFile, cfg)
+}
+
+func buildCollectors(cfg *config.Config) ([]*enabledCollector, error) {
+	var collectors []*enabledCollector
 
-		blacklistedIfaceNames := strings.Split(*excludeInterfaces, ",")
-		for index, blacklistedIfaceName := range blacklistedIfaceNames {
-			blacklistedIfaceNames[index] = strings.TrimSpace(blacklistedIfaceName)
+	for _, registration := range collector.Registrations() {
+		collectorConfig := cfg.Collectors[registration.Name]
+		settings := collectorConfig.GetSettings()
+		if !settings.Enabled {
+			continue
 		}
-    if len(blacklistedIfaceNames) == 1 && blacklistedIfaceNames[0] == "" {
-      blacklistedIfaceNames = []string{}
-    }
 
-		includedIfaceNames := strings.Split(*includeInterfaces, ",")
-		for index, includedIfaceName := range includedIfaceNames {
-			includedIfaceNames[index] = strings.TrimSpace(includedIfaceName)
+		c, err := registration.New(collectorConfig)
+		if err != nil {
+			for _, created := range collectors {
+				created.stop()
+			}
+			return nil, errors.Wrapf(err, "Could not create %s collector", registration.Name)
 		}
-    if len(includedIfaceNames) == 1 && includedIfaceNames[0] == "" {
-      includedIfaceNames = []string{}
-    }
-
-    var includeIfaceRegex *regexp.Regexp
-    var includeErr error
-    if includeInterfacesRegex != nil && *includeInterfacesRegex != "" {
-		   includeIfaceRegex, includeErr = regexp.Compile(*includeInterfacesRegex)
-    }
-    var excludeIfaceRegex *regexp.Regexp
-    var excludeErr error
-    if excludeInterfacesRegex != nil && *excludeInterfacesRegex != "" {
-		  excludeIfaceRegex, excludeErr = regexp.Compile(*excludeInterfacesRegex)
-    }
-
-		if includeErr != nil {
-			log.Errorf("Could not compile include interface regex expression \"%s\". Disabling transceiver collector.", includeErr)
-		} else if excludeErr != nil {
-			log.Errorf("Could not compile exlude interface regex expression \"%s\". Disabling transceiver collector.", excludeErr)
-    } else if len(includedIfaceNames) > 0 && len(blacklistedIfaceNames) > 0 {
-      log.Errorf("Can't include and exclude interfaces at the same time. Disabling transceiver collector.")
-		} else {
-			enabledCollectors = append(enabledCollectors, transceivercollector.NewCollector(blacklistedIfaceNames, includedIfaceNames, includeIfaceRegex, excludeIfaceRegex, true, *collectInterfaceFeatures, false))
+		timeout := settings.Timeout
+		if timeout <= 0 {
+			timeout = cfg.Timeout
 		}
+		interval := settings.Interval
+		if interval <= 0 {
+			interval = cfg.Interval
+		}
+		log.Infof("%s collector enabled", registration.Name)
+		collectors = append(collectors, &enabledCollector{
+			Collector: c,
+			name:      registration.Name,
+			timeout:   timeout,
+			interval:  interval,
+		})
 	}
-	if *hwmonCollector {
-		log.Info("hwmon collector enabled")
-		enabledCollectors = append(enabledCollectors, hwmon.NewCollector())
+	return collectors, nil
+}
+
+// initialize loads the configuration and replaces the enabled collectors. The
+// running collectors are kept if the configuration is invalid.
+func initialize() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	var labeler events.Labeler
+	if cfg.InterfaceAliasLabel {
+		labeler = addEventAliasLabels
+	}
+	manager, err := events.New(cfg.Events, labeler)
+	if err != nil {
+		return err
+	}
+	collectors, err := buildCollectors(cfg)
+	if err != nil {
+		manager.Stop()
+		return err
 	}
-	if *mstpdCollector {
-		enabledCollectors = append(enabledCollectors, mstpd.NewCollector(*mstpctlPath))
+
+	for _, c := range collectors {
+		c.start()
+	}
+
+	enabledCollectorsLock.Lock()
+	previousCollectors := enabledCollectors
+	previousManager := eventManager
+	enabledCollectors = collectors
+	interfaceAliasLabel = cfg.InterfaceAliasLabel
+	// scrapes only share collection runs within a generation, see newCoalescingGatherer
+	generation++
+	eventManager = manager
+	enabledCollectorsLock.Unlock()
+
+	// the previous manager is stopped first, so that both do not deliver
+	// the same spooled events
+	previousManager.Stop()
+	manager.Start()
+
+	for _, c := range previousCollectors {
+		c.stop()
+	}
+	return nil
+}
+
+func getEnabledCollectors() []*enabledCollector {
+	enabledCollectorsLock.RLock()
+	defer enabledCollectorsLock.RUnlock()
+	return enabledCollectors
+}
+
+func getEventManager() *events.Manager {
+	enabledCollectorsLock.RLock()
+	defer enabledCollectorsLock.RUnlock()
+	return eventManager
+}
+
+// getScrapeSettings returns the enabled collectors, whether alias labels are
+// added and the generation of the configuration they were loaded from
+func getScrapeSettings() ([]*enabledCollector, bool, uint64) {
+	enabledCollectorsLock.RLock()
+	defer enabledCollectorsLock.RUnlock()
+	return enabledCollectors, interfaceAliasLabel, generation
+}
+
+func reloadOnSighup() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		log.Info("Received SIGHUP, reloading configuration")
+		err := initialize()
+		if err != nil {
+			log.Errorf("Could not reload configuration, keeping the running one: %v", err)
+			continue
+		}
+		log.Info("Configuration reloaded")
 	}
 }
 
 func startServer() {
 	log.Infof("Starting cumulus-exporter (version: %s)", version)
-	initialize()
+	err := initialize()
+	if err != nil {
+		log.Fatalf("Could not load configuration: %v", err)
+	}
+	go reloadOnSighup()
+
+	if *maxRequests > 0 {
+		scrapeSlots = make(chan struct{}, *maxRequests)
+	}
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(`<html>
             <head><title>cumulus-exporter (Version ` + version + `)</title></head>
@@ -135,16 +242,117 @@ func startServer() {
 	})
 	http.HandleFunc(*metricsPath, handleMetricsRequest)
 
-	log.Infof("Listening on %s", *listenAddress)
-	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+	err = web.Validate(*webConfigFile)
+	if err != nil {
+		log.Fatalf("Invalid web configuration file '%s': %v", *webConfigFile, err)
+	}
+
+	var listeners []net.Listener
+	for _, address := range listenAddress {
+		listener, err := listen(address)
+		if err != nil {
+			log.Fatalf("Could not listen on %s: %v", address, err)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	systemdSocket := false
+	flags := &web.FlagConfig{
+		WebListenAddresses: (*[]string)(&listenAddress),
+		WebSystemdSocket:   &systemdSocket,
+		WebConfigFile:      webConfigFile,
+	}
+	logger := newWebLogger()
+
+	// every listener gets a server of its own, as web.Serve wraps the
+	// server's handler
+	errs := make(chan error)
+	for _, listener := range listeners {
+		go func(listener net.Listener) {
+			errs <- web.Serve(listener, &http.Server{}, flags, logger)
+		}(listener)
+	}
+	log.Fatal(<-errs)
+}
+
+// newWebLogger returns the logger used by the exporter-toolkit, writing to
+// the same output at the same level as the logrus logger
+func newWebLogger() *slog.Logger {
+	level := slog.LevelInfo
+	if log.IsLevelEnabled(log.DebugLevel) {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(log.StandardLogger().Out, &slog.HandlerOptions{
+		Level: level,
+	}))
 }
 
 func handleMetricsRequest(w http.ResponseWriter, request *http.Request) {
-	registry := prometheus.NewRegistry()
+	if scrapeSlots != nil {
+		select {
+		case scrapeSlots <- struct{}{}:
+			defer func() {
+				<-scrapeSlots
+			}()
+		default:
+			http.Error(w, "Too many concurrent scrapes", http.StatusServiceUnavailable)
+			return
+		}
+	}
 
-	registry.MustRegister(newCumulusCollector())
+	query := request.URL.Query()
+	enabled, aliasLabel, generation := getScrapeSettings()
+	collectors, err := filterCollectors(enabled, query["collect[]"], query["exclude[]"])
+	if err != nil {
+		log.Warnf("Invalid metrics request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+	// the collection run may be shared with other requests, so it must not
+	// be cancelled when this request's client goes away
+	gatherer := newCoalescingGatherer(context.WithoutCancel(request.Context()), collectors, aliasLabel, generation)
+
+	promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
 		ErrorHandling: promhttp.ContinueOnError,
 	}).ServeHTTP(w, request)
 }
+
+// filterCollectors restricts collectors to the ones named in include, if it
+// is not empty, and removes the ones named in exclude
+func filterCollectors(collectors []*enabledCollector, include []string, exclude []string) ([]*enabledCollector, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return collectors, nil
+	}
+
+	enabled := map[string]bool{}
+	for _, c := range collectors {
+		enabled[c.name] = true
+	}
+	selected := map[string]bool{}
+	for _, name := range include {
+		if !enabled[name] {
+			return nil, errors.Errorf("collector %s is not enabled", name)
+		}
+		selected[name] = true
+	}
+	excluded := map[string]bool{}
+	for _, name := range exclude {
+		if !enabled[name] {
+			return nil, errors.Errorf("collector %s is not enabled", name)
+		}
+		excluded[name] = true
+	}
+
+	var res []*enabledCollector
+	for _, c := range collectors {
+		if len(selected) > 0 && !selected[c.name] {
+			continue
+		}
+		if excluded[c.name] {
+			continue
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
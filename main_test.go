@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "Update the golden exposition files of the scrape tests")
+
+// fixtureCollectors are the collectors working from the recorded data in fixtures/example
+var fixtureCollectors = []string{"asic", "hwmon", "mstpd", "clagd", "frr", "evpn", "lldp", "ptm", "portstats", "interfaces", "linkstate"}
+
+// TestScrapeFixtures scrapes the collectors running against fixtures/example
+// and compares the result with fixtures/example.prom. Run the test with
+// -update to record the exposition after changing a collector or the fixtures.
+func TestScrapeFixtures(t *testing.T) {
+	setFlag(t, "sysroot", "fixtures/example")
+	setFlag(t, "collector.mstpd.state-enum", "true")
+	for _, name := range fixtureCollectors {
+		setFlag(t, "collector."+name, "true")
+	}
+
+	err := initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, c := range getEnabledCollectors() {
+			c.stop()
+		}
+	}()
+
+	recorder := httptest.NewRecorder()
+	handleMetricsRequest(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != 200 {
+		t.Fatalf("got status code %d: %s", recorder.Code, recorder.Body)
+	}
+	exposition := withoutVaryingSeries(recorder.Body.String())
+
+	const golden = "fixtures/example.prom"
+	if *updateGolden {
+		err = os.WriteFile(golden, []byte(exposition), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exposition != string(expected) {
+		t.Errorf("scrape differs from %s, run the test with -update and check the diff:\n%s", golden, diffLines(string(expected), exposition))
+	}
+}
+
+func setFlag(t *testing.T, name string, value string) {
+	t.Helper()
+	err := flag.Set(name, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// withoutVaryingSeries removes the series whose value differs between runs
+func withoutVaryingSeries(exposition string) string {
+	var b strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(exposition))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "cumulus_exporter_collector_duration_seconds{") {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// diffLines lists the lines only found in expected or actual
+func diffLines(expected string, actual string) string {
+	expectedLines := map[string]bool{}
+	for _, line := range strings.Split(expected, "\n") {
+		expectedLines[line] = true
+	}
+	actualLines := map[string]bool{}
+	for _, line := range strings.Split(actual, "\n") {
+		actualLines[line] = true
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(expected, "\n") {
+		if !actualLines[line] {
+			b.WriteString("- " + line + "\n")
+		}
+	}
+	for _, line := range strings.Split(actual, "\n") {
+		if !expectedLines[line] {
+			b.WriteString("+ " + line + "\n")
+		}
+	}
+	return b.String()
+}
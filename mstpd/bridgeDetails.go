@@ -0,0 +1,45 @@
+package mstpd
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ShowBridgeResult stores the parsed information of a `mstpctl showbridge` command
+type ShowBridgeResult map[string]*BridgeDetails
+
+// BridgeDetails store parsed bridge information returned for a single bridge by `mstpctl showbridge`
+type BridgeDetails struct {
+	Enabled                 bool    `json:"enabled"`
+	BridgeID                string  `json:"bridgeId"`
+	DesignatedRoot          string  `json:"designatedRoot"`
+	RegionalRoot            string  `json:"regionalRoot"`
+	RootPort                string  `json:"rootPort"`
+	PathCost                float64 `json:"pathCost"`
+	InternalPathCost        float64 `json:"internalPathCost"`
+	MaxAge                  float64 `json:"maxAge"`
+	BridgeMaxAge            float64 `json:"bridgeMaxAge"`
+	ForwardDelay            float64 `json:"forwardDelay"`
+	BridgeForwardDelay      float64 `json:"bridgeForwardDelay"`
+	TxHoldCount             float64 `json:"txHoldCount"`
+	MaxHops                 float64 `json:"maxHops"`
+	HelloTime               float64 `json:"helloTime"`
+	BridgeHelloTime         float64 `json:"bridgeHelloTime"`
+	ForceProtocolVersion    string  `json:"forceProtocolVersion"`
+	TimeSinceTopologyChange float64 `json:"timeSinceTopologyChange"`
+	TopologyChangeCount     float64 `json:"topologyChangeCount"`
+	TopologyChange          bool    `json:"topologyChange"`
+	TopologyChangePort      string  `json:"topologyChangePort"`
+	LastTopologyChangePort  string  `json:"lastTopologyChangePort"`
+}
+
+// BridgeIDPriority returns the priority encoded in a bridge ID like
+// 8.000.44:38:39:00:00:01, whose first digit is the priority in units of 4096
+func BridgeIDPriority(bridgeID string) (float64, bool) {
+	fields := strings.SplitN(bridgeID, ".", 2)
+	priority, err := strconv.ParseUint(fields[0], 16, 8)
+	if err != nil || len(fields) < 2 {
+		return 0, false
+	}
+	return float64(priority * 4096), true
+}
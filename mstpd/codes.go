@@ -0,0 +1,78 @@
+package mstpd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+var (
+	// Roles are the port roles known to mstpd, their index is exposed as role code
+	Roles = []string{"Disabled", "Root", "Designated", "Alternate", "Backup", "Master"}
+	// States are the port states known to mstpd, their index is exposed as state code
+	States = []string{"discarding", "learning", "forwarding"}
+)
+
+// Code returns the index of value in known ignoring case, or -1 if it is not known
+func Code(known []string, value string) float64 {
+	for i, k := range known {
+		if strings.EqualFold(k, value) {
+			return float64(i)
+		}
+	}
+	return -1
+}
+
+// codeDescs are the descriptions of the numeric role and state codes and of the
+// series per known role and state
+type codeDescs struct {
+	roleCode  *prometheus.Desc
+	stateCode *prometheus.Desc
+	role      *prometheus.Desc
+	state     *prometheus.Desc
+}
+
+func newCodeDescs(metricPrefix string, labels []string) *codeDescs {
+	return &codeDescs{
+		roleCode:  prometheus.NewDesc(metricPrefix+"role_code", "role as code: -1 unknown, "+describeCodes(Roles), labels, nil),
+		stateCode: prometheus.NewDesc(metricPrefix+"state_code", "state as code: -1 unknown, "+describeCodes(States), labels, nil),
+		role:      prometheus.NewDesc(metricPrefix+"role", "1 for the current role, 0 for all other known roles", append(labels, "role"), nil),
+		state:     prometheus.NewDesc(metricPrefix+"state", "1 for the current state, 0 for all other known states", append(labels, "state"), nil),
+	}
+}
+
+func describeCodes(known []string) string {
+	descriptions := make([]string, 0, len(known))
+	for i, k := range known {
+		descriptions = append(descriptions, strconv.Itoa(i)+" "+strings.ToLower(k))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+func (d *codeDescs) describe(ch chan<- *prometheus.Desc) {
+	ch <- d.roleCode
+	ch <- d.stateCode
+	ch <- d.role
+	ch <- d.state
+}
+
+// collect exposes role and state as codes and, with enum, as one series per
+// known role and state, which unlike the info metrics do not go stale when
+// the port changes its state
+func (d *codeDescs) collect(labels []string, role string, state string, enum bool, metrics chan<- prometheus.Metric) {
+	roleCode := Code(Roles, role)
+	stateCode := Code(States, state)
+	metrics <- prometheus.MustNewConstMetric(d.roleCode, prometheus.GaugeValue, roleCode, labels...)
+	metrics <- prometheus.MustNewConstMetric(d.stateCode, prometheus.GaugeValue, stateCode, labels...)
+	if !enum {
+		return
+	}
+	for i, r := range Roles {
+		metrics <- prometheus.MustNewConstMetric(d.role, prometheus.GaugeValue, util.BoolToFloat64(roleCode == float64(i)), append(labels, r)...)
+	}
+	for i, s := range States {
+		metrics <- prometheus.MustNewConstMetric(d.state, prometheus.GaugeValue, util.BoolToFloat64(stateCode == float64(i)), append(labels, s)...)
+	}
+}
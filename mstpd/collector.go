@@ -1,13 +1,22 @@
 package mstpd
 
 import (
+	"context"
+	"flag"
+	"strconv"
+
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
 )
 
 const prefix = "mstpd_"
 
 var (
+	mstpctlPath = flag.String("collector.mstpd.mstpctl-path", "/sbin/mstpctl", "mstpctl binary path")
+	stateEnum   = flag.Bool("collector.mstpd.state-enum", false, "Expose one series per known port role and state with value 0 or 1 in addition to the info metrics")
+
 	enabledDesc             *prometheus.Desc
 	roleInfoDesc            *prometheus.Desc
 	stateInfoDesc           *prometheus.Desc
@@ -33,27 +42,91 @@ var (
 	clagRoleInfoDesc        *prometheus.Desc
 	clagDualConnMacInfoDesc *prometheus.Desc
 	clagSystemMacInfoDesc   *prometheus.Desc
+	dsgnRootInfoDesc        *prometheus.Desc
+	dsgnRegRootInfoDesc     *prometheus.Desc
+	dsgnBrInfoDesc          *prometheus.Desc
+	dsgnPortInfoDesc        *prometheus.Desc
+	adminPointToPointDesc   *prometheus.Desc
+	clagRemotePortIDDesc    *prometheus.Desc
+
+	bridgeInfoDesc                    *prometheus.Desc
+	bridgeEnabledDesc                 *prometheus.Desc
+	bridgeRootDesc                    *prometheus.Desc
+	bridgePriorityDesc                *prometheus.Desc
+	bridgeRootPriorityDesc            *prometheus.Desc
+	bridgeRootPathCostDesc            *prometheus.Desc
+	bridgeInternalPathCostDesc        *prometheus.Desc
+	bridgeTopologyChangesDesc         *prometheus.Desc
+	bridgeTopologyChangeDesc          *prometheus.Desc
+	bridgeTopologyChangeInfoDesc      *prometheus.Desc
+	bridgeTimeSinceTopologyChangeDesc *prometheus.Desc
+	bridgeMaxAgeDesc                  *prometheus.Desc
+	bridgeForwardDelayDesc            *prometheus.Desc
+	bridgeHelloTimeDesc               *prometheus.Desc
+	bridgeTxHoldCountDesc             *prometheus.Desc
+	bridgeMaxHopsDesc                 *prometheus.Desc
+	bridgeRootChangesDesc             *prometheus.Desc
+	bridgeRootLastChangeDesc          *prometheus.Desc
+
+	mstiRoleInfoDesc  *prometheus.Desc
+	mstiStateInfoDesc *prometheus.Desc
+	mstiVlansInfoDesc *prometheus.Desc
+
+	portCodeDescs *codeDescs
+	mstiCodeDescs *codeDescs
 )
 
+// Config configures the mstpd collector
+type Config struct {
+	collector.Settings `yaml:",inline"`
+	MstpctlPath        string `yaml:"mstpctl_path"`
+	StateEnum          bool   `yaml:"state_enum"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	if c.MstpctlPath == "" {
+		return errors.New("mstpctl_path must not be empty")
+	}
+	return nil
+}
+
 // Collector collects metrics exposed by mstpctl
 type Collector struct {
 	mstpctlPath string
+	stateEnum   bool
+	roots       *rootTracker
 }
 
-// NewCollector returns a new Collector instance
-func NewCollector(mstpctlPath string) *Collector {
+// NewCollector returns a new Collector instance. With stateEnum every known
+// port role and state is exposed as a series of its own.
+func NewCollector(mstpctlPath string, stateEnum bool) *Collector {
 	return &Collector{
 		mstpctlPath: mstpctlPath,
+		stateEnum:   stateEnum,
+		roots:       newRootTracker(),
 	}
 }
 
 func init() {
+	collector.DeprecatedAlias("collectors.mstpd.mstpctl-path", "collector.mstpd.mstpctl-path")
+	collector.Register("mstpd", false, func() collector.Config {
+		return &Config{
+			MstpctlPath: *mstpctlPath,
+			StateEnum:   *stateEnum,
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		config := cfg.(*Config)
+		return NewCollector(config.MstpctlPath, config.StateEnum), nil
+	})
+
 	labels := []string{"bridge_name", "interface"}
 	enabledDesc = prometheus.NewDesc(prefix+"enabled_bool", "enabled", labels, nil)
 	roleLabels := append(labels, "role")
 	roleInfoDesc = prometheus.NewDesc(prefix+"role_info", "role", roleLabels, nil)
 	stateLabels := append(labels, "state")
 	stateInfoDesc = prometheus.NewDesc(prefix+"state_info", "state", stateLabels, nil)
+	portCodeDescs = newCodeDescs(prefix, labels)
 	extPortCostDesc = prometheus.NewDesc(prefix+"ext_port_cost", "external port cost", labels, nil)
 	adminExtPortCostDesc = prometheus.NewDesc(prefix+"admin_ext_port_cost", "admin external port cost", labels, nil)
 	intPortCostDesc = prometheus.NewDesc(prefix+"int_port_cost", "internal port cost", labels, nil)
@@ -79,6 +152,38 @@ func init() {
 	clagDualConnMacInfoDesc = prometheus.NewDesc(prefix+"clag_dual_conn_mac_info", "clag dual conn mac", clagDualConnMacLabels, nil)
 	clagSystemMacInfoLabels := append(labels, "clag_system_mac")
 	clagSystemMacInfoDesc = prometheus.NewDesc(prefix+"clag_system_mac_info", "clag system mac", clagSystemMacInfoLabels, nil)
+	dsgnRootInfoDesc = prometheus.NewDesc(prefix+"dsgn_root_info", "dsgn root", append(labels, "dsgn_root"), nil)
+	dsgnRegRootInfoDesc = prometheus.NewDesc(prefix+"dsgn_reg_root_info", "dsgn regional root", append(labels, "dsgn_reg_root"), nil)
+	dsgnBrInfoDesc = prometheus.NewDesc(prefix+"dsgn_br_info", "dsgn bridge", append(labels, "dsgn_br"), nil)
+	dsgnPortInfoDesc = prometheus.NewDesc(prefix+"dsgn_port_info", "dsgn port", append(labels, "dsgn_port"), nil)
+	adminPointToPointDesc = prometheus.NewDesc(prefix+"admin_point_to_point_info", "admin point-to-point", append(labels, "admin_point_to_point"), nil)
+	clagRemotePortIDDesc = prometheus.NewDesc(prefix+"clag_remote_port_id_info", "clag remote port id", append(labels, "clag_remote_port_id"), nil)
+
+	bridgeLabels := []string{"bridge_name"}
+	bridgeInfoDesc = prometheus.NewDesc(prefix+"bridge_info", "bridge ID, designated root, regional root, root port and protocol version of the bridge", append(bridgeLabels, "bridge_id", "designated_root", "regional_root", "root_port", "protocol_version"), nil)
+	bridgeEnabledDesc = prometheus.NewDesc(prefix+"bridge_enabled_bool", "bridge enabled", bridgeLabels, nil)
+	bridgeRootDesc = prometheus.NewDesc(prefix+"bridge_root_bool", "bridge is the designated root", bridgeLabels, nil)
+	bridgePriorityDesc = prometheus.NewDesc(prefix+"bridge_priority", "bridge priority", bridgeLabels, nil)
+	bridgeRootPriorityDesc = prometheus.NewDesc(prefix+"bridge_root_priority", "priority of the designated root", bridgeLabels, nil)
+	bridgeRootPathCostDesc = prometheus.NewDesc(prefix+"bridge_root_path_cost", "path cost to the designated root", bridgeLabels, nil)
+	bridgeInternalPathCostDesc = prometheus.NewDesc(prefix+"bridge_internal_root_path_cost", "internal path cost to the regional root", bridgeLabels, nil)
+	bridgeTopologyChangesDesc = prometheus.NewDesc(prefix+"bridge_topology_changes_total", "number of topology changes", bridgeLabels, nil)
+	bridgeTopologyChangeDesc = prometheus.NewDesc(prefix+"bridge_topology_change_bool", "topology change in progress", bridgeLabels, nil)
+	bridgeTopologyChangeInfoDesc = prometheus.NewDesc(prefix+"bridge_topology_change_info", "ports the current and the last topology change were detected on", append(bridgeLabels, "port", "last_port"), nil)
+	bridgeTimeSinceTopologyChangeDesc = prometheus.NewDesc(prefix+"bridge_time_since_topology_change_seconds", "time since the last topology change in seconds", bridgeLabels, nil)
+	bridgeMaxAgeDesc = prometheus.NewDesc(prefix+"bridge_max_age_seconds", "max age in seconds", bridgeLabels, nil)
+	bridgeForwardDelayDesc = prometheus.NewDesc(prefix+"bridge_forward_delay_seconds", "forward delay in seconds", bridgeLabels, nil)
+	bridgeHelloTimeDesc = prometheus.NewDesc(prefix+"bridge_hello_time_seconds", "hello time in seconds", bridgeLabels, nil)
+	bridgeTxHoldCountDesc = prometheus.NewDesc(prefix+"bridge_tx_hold_count", "transmit hold count", bridgeLabels, nil)
+	bridgeMaxHopsDesc = prometheus.NewDesc(prefix+"bridge_max_hops", "max hops", bridgeLabels, nil)
+	bridgeRootChangesDesc = prometheus.NewDesc(prefix+"bridge_root_changes_total", "number of designated root changes observed since the collector was created", bridgeLabels, nil)
+	bridgeRootLastChangeDesc = prometheus.NewDesc(prefix+"bridge_root_last_change_timestamp_seconds", "time the last designated root change was observed", bridgeLabels, nil)
+
+	mstiLabels := []string{"bridge_name", "msti", "interface"}
+	mstiRoleInfoDesc = prometheus.NewDesc(prefix+"msti_role_info", "role in the MSTI", append(mstiLabels, "role"), nil)
+	mstiStateInfoDesc = prometheus.NewDesc(prefix+"msti_state_info", "state in the MSTI", append(mstiLabels, "state"), nil)
+	mstiCodeDescs = newCodeDescs(prefix+"msti_", mstiLabels)
+	mstiVlansInfoDesc = prometheus.NewDesc(prefix+"msti_vlans_info", "VLANs mapped to the MSTI", []string{"bridge_name", "msti", "vlans"}, nil)
 }
 
 // Describe implements collector.Collector interface's Describe function
@@ -86,6 +191,7 @@ func (*Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- enabledDesc
 	ch <- roleInfoDesc
 	ch <- stateInfoDesc
+	portCodeDescs.describe(ch)
 	ch <- extPortCostDesc
 	ch <- adminExtPortCostDesc
 	ch <- intPortCostDesc
@@ -108,28 +214,107 @@ func (*Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- clagRoleInfoDesc
 	ch <- clagDualConnMacInfoDesc
 	ch <- clagSystemMacInfoDesc
+	ch <- dsgnRootInfoDesc
+	ch <- dsgnRegRootInfoDesc
+	ch <- dsgnBrInfoDesc
+	ch <- dsgnPortInfoDesc
+	ch <- adminPointToPointDesc
+	ch <- clagRemotePortIDDesc
+	ch <- bridgeInfoDesc
+	ch <- bridgeEnabledDesc
+	ch <- bridgeRootDesc
+	ch <- bridgePriorityDesc
+	ch <- bridgeRootPriorityDesc
+	ch <- bridgeRootPathCostDesc
+	ch <- bridgeInternalPathCostDesc
+	ch <- bridgeTopologyChangesDesc
+	ch <- bridgeTopologyChangeDesc
+	ch <- bridgeTopologyChangeInfoDesc
+	ch <- bridgeTimeSinceTopologyChangeDesc
+	ch <- bridgeMaxAgeDesc
+	ch <- bridgeForwardDelayDesc
+	ch <- bridgeHelloTimeDesc
+	ch <- bridgeTxHoldCountDesc
+	ch <- bridgeMaxHopsDesc
+	ch <- bridgeRootChangesDesc
+	ch <- bridgeRootLastChangeDesc
+	ch <- mstiRoleInfoDesc
+	ch <- mstiStateInfoDesc
+	ch <- mstiVlansInfoDesc
+	mstiCodeDescs.describe(ch)
 }
 
 // Collect implements collector.Collector interface's Collect function
-func (c *Collector) Collect(metrics chan<- prometheus.Metric, errorChan chan error, done chan struct{}) {
-	defer func() {
-		done <- struct{}{}
-	}()
-
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
 	bridges, err := GetBridges()
 	if err != nil {
 		errorChan <- errors.Wrap(err, "Could not retrieve list of system's bridge interfaces")
+	} else {
+		c.roots.prune(bridges)
 	}
 
 	for _, bridge := range bridges {
-		showPortDetail, err := ShowPortDetail(c.mstpctlPath, bridge)
+		showBridge, err := ShowBridge(ctx, c.mstpctlPath, bridge)
+		if err != nil {
+			errorChan <- errors.Wrapf(err, "Show bridge failed for interface %s", bridge)
+		}
+		for bridgeName, bridgeDetails := range showBridge {
+			collectForBridge(bridgeName, bridgeDetails, metrics)
+			c.collectRootChanges(bridgeName, bridgeDetails, metrics)
+		}
+
+		showPortDetail, err := ShowPortDetail(ctx, c.mstpctlPath, bridge)
 		if err != nil {
 			errorChan <- errors.Wrapf(err, "Show port failed for interface %s", bridge)
 		}
+		var ports []string
 		for _, portData := range showPortDetail {
-			for _, portDetails := range portData {
+			for portName, portDetails := range portData {
 				collectForPort(portDetails, metrics)
+				portCodeDescs.collect([]string{portDetails.BridgeName, portDetails.PortName}, portDetails.Role, portDetails.State, c.stateEnum, metrics)
+				ports = append(ports, portName)
+			}
+		}
+
+		c.collectMstis(ctx, bridge, ports, metrics, errorChan)
+	}
+}
+
+// collectMstis exposes the role and state of ports in every MSTI but the CIST,
+// which is covered by the port details
+func (c *Collector) collectMstis(ctx context.Context, bridge string, ports []string, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	mstis, err := ShowMstiList(ctx, c.mstpctlPath, bridge)
+	if err != nil {
+		errorChan <- errors.Wrapf(err, "Show MSTI list failed for interface %s", bridge)
+		return
+	}
+	if len(mstis) <= 1 {
+		return
+	}
+
+	mstiVlans, err := ShowMstiVlans(ctx, c.mstpctlPath, bridge)
+	if err != nil {
+		errorChan <- errors.Wrapf(err, "Show VLAN to MSTI mapping failed for interface %s", bridge)
+	}
+	for msti, vlans := range mstiVlans {
+		metrics <- prometheus.MustNewConstMetric(mstiVlansInfoDesc, prometheus.GaugeValue, 1.0, bridge, strconv.Itoa(int(msti)), vlans)
+	}
+
+	for _, msti := range mstis {
+		if msti == 0 {
+			continue
+		}
+		for _, port := range ports {
+			if ctx.Err() != nil {
+				return
+			}
+			treePortDetails, err := ShowTreePort(ctx, c.mstpctlPath, bridge, port, msti)
+			if err != nil {
+				errorChan <- errors.Wrapf(err, "Show tree port failed for interface %s", port)
+				continue
 			}
+			collectForTreePort(treePortDetails, metrics)
+			mstiCodeDescs.collect([]string{bridge, strconv.Itoa(int(msti)), port}, treePortDetails.Role, treePortDetails.State, c.stateEnum, metrics)
 		}
 	}
 }
@@ -139,9 +324,51 @@ func (*Collector) Name() string {
 	return "MstpdCollector"
 }
 
+// collectRootChanges compares the designated root with the one seen by the
+// previous collection, as mstpd does not count root changes
+func (c *Collector) collectRootChanges(bridgeName string, bridgeDetails *BridgeDetails, metrics chan<- prometheus.Metric) {
+	history := c.roots.observe(bridgeName, bridgeDetails.DesignatedRoot)
+	metrics <- prometheus.MustNewConstMetric(bridgeRootChangesDesc, prometheus.CounterValue, float64(history.changes), bridgeName)
+	if !history.lastChange.IsZero() {
+		metrics <- prometheus.MustNewConstMetric(bridgeRootLastChangeDesc, prometheus.GaugeValue, float64(history.lastChange.UnixNano())/1e9, bridgeName)
+	}
+}
+
+func collectForTreePort(treePortDetails *TreePortDetails, metrics chan<- prometheus.Metric) {
+	labels := []string{treePortDetails.BridgeName, strconv.Itoa(int(treePortDetails.MSTI)), treePortDetails.PortName}
+	metrics <- prometheus.MustNewConstMetric(mstiRoleInfoDesc, prometheus.GaugeValue, 1.0, append(labels, treePortDetails.Role)...)
+	metrics <- prometheus.MustNewConstMetric(mstiStateInfoDesc, prometheus.GaugeValue, 1.0, append(labels, treePortDetails.State)...)
+}
+
+func collectForBridge(bridgeName string, bridgeDetails *BridgeDetails, metrics chan<- prometheus.Metric) {
+	labels := []string{bridgeName}
+	infoLabels := append(labels, bridgeDetails.BridgeID, bridgeDetails.DesignatedRoot, bridgeDetails.RegionalRoot, bridgeDetails.RootPort, bridgeDetails.ForceProtocolVersion)
+	metrics <- prometheus.MustNewConstMetric(bridgeInfoDesc, prometheus.GaugeValue, 1.0, infoLabels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeEnabledDesc, prometheus.GaugeValue, util.BoolToFloat64(bridgeDetails.Enabled), labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeRootDesc, prometheus.GaugeValue, util.BoolToFloat64(bridgeDetails.BridgeID == bridgeDetails.DesignatedRoot), labels...)
+	if priority, ok := BridgeIDPriority(bridgeDetails.BridgeID); ok {
+		metrics <- prometheus.MustNewConstMetric(bridgePriorityDesc, prometheus.GaugeValue, priority, labels...)
+	}
+	if priority, ok := BridgeIDPriority(bridgeDetails.DesignatedRoot); ok {
+		metrics <- prometheus.MustNewConstMetric(bridgeRootPriorityDesc, prometheus.GaugeValue, priority, labels...)
+	}
+	metrics <- prometheus.MustNewConstMetric(bridgeRootPathCostDesc, prometheus.GaugeValue, bridgeDetails.PathCost, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeInternalPathCostDesc, prometheus.GaugeValue, bridgeDetails.InternalPathCost, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeTopologyChangesDesc, prometheus.CounterValue, bridgeDetails.TopologyChangeCount, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeTopologyChangeDesc, prometheus.GaugeValue, util.BoolToFloat64(bridgeDetails.TopologyChange), labels...)
+	topologyChangeLabels := append(labels, bridgeDetails.TopologyChangePort, bridgeDetails.LastTopologyChangePort)
+	metrics <- prometheus.MustNewConstMetric(bridgeTopologyChangeInfoDesc, prometheus.GaugeValue, 1.0, topologyChangeLabels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeTimeSinceTopologyChangeDesc, prometheus.GaugeValue, bridgeDetails.TimeSinceTopologyChange, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeMaxAgeDesc, prometheus.GaugeValue, bridgeDetails.MaxAge, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeForwardDelayDesc, prometheus.GaugeValue, bridgeDetails.ForwardDelay, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeHelloTimeDesc, prometheus.GaugeValue, bridgeDetails.HelloTime, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeTxHoldCountDesc, prometheus.GaugeValue, bridgeDetails.TxHoldCount, labels...)
+	metrics <- prometheus.MustNewConstMetric(bridgeMaxHopsDesc, prometheus.GaugeValue, bridgeDetails.MaxHops, labels...)
+}
+
 func collectForPort(portDetails *PortDetails, metrics chan<- prometheus.Metric) {
 	labels := []string{portDetails.BridgeName, portDetails.PortName}
-	metrics <- prometheus.MustNewConstMetric(enabledDesc, prometheus.GaugeValue, BoolToFloat64(portDetails.Enabled), labels...)
+	metrics <- prometheus.MustNewConstMetric(enabledDesc, prometheus.GaugeValue, util.BoolToFloat64(portDetails.Enabled), labels...)
 	roleLabels := append(labels, portDetails.Role)
 	metrics <- prometheus.MustNewConstMetric(roleInfoDesc, prometheus.GaugeValue, 1.0, roleLabels...)
 	stateLabels := append(labels, portDetails.State)
@@ -152,23 +379,29 @@ func collectForPort(portDetails *PortDetails, metrics chan<- prometheus.Metric)
 	metrics <- prometheus.MustNewConstMetric(adminIntPortCostDesc, prometheus.GaugeValue, portDetails.AdminIntPortCost, labels...)
 	metrics <- prometheus.MustNewConstMetric(dsgnExtCostDesc, prometheus.GaugeValue, portDetails.DsgnExtCost, labels...)
 	metrics <- prometheus.MustNewConstMetric(dsgnIntCostDesc, prometheus.GaugeValue, portDetails.DsgnIntCost, labels...)
-	metrics <- prometheus.MustNewConstMetric(adminEdgePortDesc, prometheus.GaugeValue, BoolToFloat64(portDetails.AdminEdgePort), labels...)
-	metrics <- prometheus.MustNewConstMetric(autoEdgePortDesc, prometheus.GaugeValue, BoolToFloat64(portDetails.AutoEdgePort), labels...)
-	metrics <- prometheus.MustNewConstMetric(operEdgePortDesc, prometheus.GaugeValue, BoolToFloat64(portDetails.OperEdgePort), labels...)
-	metrics <- prometheus.MustNewConstMetric(pointToPointDesc, prometheus.GaugeValue, BoolToFloat64(portDetails.PointToPoint), labels...)
+	metrics <- prometheus.MustNewConstMetric(adminEdgePortDesc, prometheus.GaugeValue, util.BoolToFloat64(portDetails.AdminEdgePort), labels...)
+	metrics <- prometheus.MustNewConstMetric(autoEdgePortDesc, prometheus.GaugeValue, util.BoolToFloat64(portDetails.AutoEdgePort), labels...)
+	metrics <- prometheus.MustNewConstMetric(operEdgePortDesc, prometheus.GaugeValue, util.BoolToFloat64(portDetails.OperEdgePort), labels...)
+	metrics <- prometheus.MustNewConstMetric(pointToPointDesc, prometheus.GaugeValue, util.BoolToFloat64(portDetails.PointToPoint), labels...)
 	metrics <- prometheus.MustNewConstMetric(portHelloTimeDesc, prometheus.GaugeValue, portDetails.PortHelloTime, labels...)
-	metrics <- prometheus.MustNewConstMetric(bpduGuardPortDesc, prometheus.GaugeValue, BoolToFloat64(portDetails.BpduGuardPort), labels...)
+	metrics <- prometheus.MustNewConstMetric(bpduGuardPortDesc, prometheus.GaugeValue, util.BoolToFloat64(portDetails.BpduGuardPort), labels...)
 	metrics <- prometheus.MustNewConstMetric(numTxBpduDesc, prometheus.GaugeValue, portDetails.NumTxBpdu, labels...)
 	metrics <- prometheus.MustNewConstMetric(numTxTcnDesc, prometheus.GaugeValue, portDetails.NumTxTcn, labels...)
 	metrics <- prometheus.MustNewConstMetric(numRxBpduDesc, prometheus.GaugeValue, portDetails.NumRxBpdu, labels...)
 	metrics <- prometheus.MustNewConstMetric(numRxTcnDesc, prometheus.GaugeValue, portDetails.NumRxTcn, labels...)
 	metrics <- prometheus.MustNewConstMetric(numTransFwdDesc, prometheus.GaugeValue, portDetails.NumTransFwd, labels...)
 	metrics <- prometheus.MustNewConstMetric(numTransBlkDesc, prometheus.GaugeValue, portDetails.NumTransBlk, labels...)
-	metrics <- prometheus.MustNewConstMetric(bpduFilterPortDesc, prometheus.GaugeValue, BoolToFloat64(portDetails.BpduFilterPort), labels...)
+	metrics <- prometheus.MustNewConstMetric(bpduFilterPortDesc, prometheus.GaugeValue, util.BoolToFloat64(portDetails.BpduFilterPort), labels...)
 	clagRoleLabels := append(labels, portDetails.ClagRole)
 	metrics <- prometheus.MustNewConstMetric(clagRoleInfoDesc, prometheus.GaugeValue, 1.0, clagRoleLabels...)
 	clagDualConnMacLabels := append(labels, portDetails.ClagDualConnMac)
 	metrics <- prometheus.MustNewConstMetric(clagDualConnMacInfoDesc, prometheus.GaugeValue, 1.0, clagDualConnMacLabels...)
 	clagSystemMacInfoLabels := append(labels, portDetails.ClagSystemMac)
 	metrics <- prometheus.MustNewConstMetric(clagSystemMacInfoDesc, prometheus.GaugeValue, 1.0, clagSystemMacInfoLabels...)
+	metrics <- prometheus.MustNewConstMetric(dsgnRootInfoDesc, prometheus.GaugeValue, 1.0, append(labels, portDetails.DsgnRoot)...)
+	metrics <- prometheus.MustNewConstMetric(dsgnRegRootInfoDesc, prometheus.GaugeValue, 1.0, append(labels, portDetails.DsgnRegRoot)...)
+	metrics <- prometheus.MustNewConstMetric(dsgnBrInfoDesc, prometheus.GaugeValue, 1.0, append(labels, portDetails.DsgnBr)...)
+	metrics <- prometheus.MustNewConstMetric(dsgnPortInfoDesc, prometheus.GaugeValue, 1.0, append(labels, portDetails.DsgnPort)...)
+	metrics <- prometheus.MustNewConstMetric(adminPointToPointDesc, prometheus.GaugeValue, 1.0, append(labels, portDetails.AdminPointToPoint)...)
+	metrics <- prometheus.MustNewConstMetric(clagRemotePortIDDesc, prometheus.GaugeValue, 1.0, append(labels, portDetails.ClagRemotePortID)...)
 }
@@ -0,0 +1,206 @@
+package mstpd
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// treePortKeys are the keys of the two column layout of `mstpctl showtreeport`.
+	// Values may be empty, so the columns are split at the keys instead of the
+	// spaces between them.
+	treePortKeys = []string{
+		"port id",
+		"role",
+		"state",
+		"internal port cost",
+		"admin int. cost",
+		"designated regional root",
+		"dsgn int. cost",
+		"designated bridge",
+		"designated port",
+		"disputed",
+	}
+	// allocationRegex matches the lines of `mstpctl showvid2fid` and `mstpctl showfid2mstid`
+	allocationRegex = regexp.MustCompile(`^\s*(?:FID|MSTID)\s+(\d+):\s*(.*)$`)
+)
+
+// TreePortDetails store parsed port information returned for a single port and MSTI by `mstpctl showtreeport`
+type TreePortDetails struct {
+	BridgeName string
+	PortName   string
+	MSTI       uint16
+	Role       string
+	State      string
+}
+
+// parseMstiList parses the output of `mstpctl showmstilist`:
+//
+//	bridge list of known MSTIs:
+//	 0 1 2
+func parseMstiList(output []byte) ([]uint16, error) {
+	_, list, found := strings.Cut(string(output), ":")
+	if !found {
+		return nil, errors.New("Unexpected MSTI list")
+	}
+	var res []uint16
+	for _, field := range strings.Fields(list) {
+		msti, err := strconv.ParseUint(field, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse MSTI %s", field)
+		}
+		res = append(res, uint16(msti))
+	}
+	return res, nil
+}
+
+// parseTreePort parses the two column key / value output of `mstpctl showtreeport`:
+//
+//	bridge:swp1 MSTI 1
+//	 port id          8.001                    role                   Designated
+//	 state            forwarding              internal port cost     2000
+func parseTreePort(output []byte) map[string]string {
+	res := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		keys := findTreePortKeys(line)
+		for i, key := range keys {
+			end := len(line)
+			if i+1 < len(keys) {
+				end = keys[i+1].start
+			}
+			res[key.name] = strings.TrimSpace(line[key.start+len(key.name) : end])
+		}
+	}
+	return res
+}
+
+type treePortKey struct {
+	name  string
+	start int
+}
+
+// findTreePortKeys returns the keys of treePortKeys found in line ordered by
+// position. Keys have to be preceded and followed by a space or the line's
+// start or end.
+func findTreePortKeys(line string) []treePortKey {
+	var res []treePortKey
+	for _, name := range treePortKeys {
+		for offset := 0; ; {
+			i := strings.Index(line[offset:], name)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			end := start + len(name)
+			offset = end
+			if (start == 0 || line[start-1] == ' ') && (end == len(line) || line[end] == ' ') {
+				res = append(res, treePortKey{name: name, start: start})
+				break
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].start < res[j].start
+	})
+	return res
+}
+
+// parseAllocations parses the output of `mstpctl showvid2fid` or `mstpctl showfid2mstid`
+// into a map of the allocated VLAN or FID to the FID or MSTI it is allocated to:
+//
+//	bridge VID-to-FID allocation table:
+//	  FID 0: 1-99,101-4094
+//	  FID 1: 100
+func parseAllocations(output []byte) (map[uint16]uint16, error) {
+	res := map[uint16]uint16{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		match := allocationRegex.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		target, err := strconv.ParseUint(match[1], 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse %s", match[1])
+		}
+		ids, err := parseRanges(match[2])
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			res[id] = uint16(target)
+		}
+	}
+	return res, nil
+}
+
+// parseRanges parses a list of ranges like 1-99,101,103-4094
+func parseRanges(ranges string) ([]uint16, error) {
+	var res []uint16
+	for _, r := range strings.Split(ranges, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(r, "-")
+		if !isRange {
+			to = from
+		}
+		start, err := strconv.ParseUint(from, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse range %s", r)
+		}
+		end, err := strconv.ParseUint(to, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not parse range %s", r)
+		}
+		for id := start; id <= end; id++ {
+			res = append(res, uint16(id))
+		}
+	}
+	return res, nil
+}
+
+// formatRanges formats ids as a list of ranges like 1-99,101,103-4094
+func formatRanges(ids []uint16) string {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	var ranges []string
+	for i := 0; i < len(ids); {
+		j := i
+		for j+1 < len(ids) && ids[j+1] == ids[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(int(ids[i])))
+		} else {
+			ranges = append(ranges, strconv.Itoa(int(ids[i]))+"-"+strconv.Itoa(int(ids[j])))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
+
+// MstiVlans combines the VLAN to FID and FID to MSTI allocations into the
+// VLANs of every MSTI, formatted as list of ranges
+func MstiVlans(vid2fid map[uint16]uint16, fid2msti map[uint16]uint16) map[uint16]string {
+	vlans := map[uint16][]uint16{}
+	for vid, fid := range vid2fid {
+		msti := fid2msti[fid]
+		vlans[msti] = append(vlans[msti], vid)
+	}
+	res := map[uint16]string{}
+	for msti, ids := range vlans {
+		res[msti] = formatRanges(ids)
+	}
+	return res
+}
@@ -0,0 +1,218 @@
+package mstpd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTreePort(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected map[string]string
+	}{
+		{
+			name: "designated port",
+			output: `bridge:swp1 MSTI 1
+ port id          8.001                    role                   Designated
+ state            forwarding              internal port cost     2000
+ admin int. cost   0                      designated regional root  8.000.44:38:39:00:00:01
+ dsgn int. cost   0                      designated bridge      8.000.44:38:39:00:00:01
+ designated port  8.001                    disputed               no
+`,
+			expected: map[string]string{
+				"port id":                  "8.001",
+				"role":                     "Designated",
+				"state":                    "forwarding",
+				"internal port cost":       "2000",
+				"admin int. cost":          "0",
+				"designated regional root": "8.000.44:38:39:00:00:01",
+				"dsgn int. cost":           "0",
+				"designated bridge":        "8.000.44:38:39:00:00:01",
+				"designated port":          "8.001",
+				"disputed":                 "no",
+			},
+		},
+		{
+			name: "empty values",
+			output: `bridge:swp2 MSTI 2
+ port id          8.002                    role                   
+ state                                    internal port cost     2000
+ admin int. cost   0                      designated regional root  
+ dsgn int. cost   0                      designated bridge      8.000.44:38:39:00:00:01
+ designated port                           disputed               no
+`,
+			expected: map[string]string{
+				"port id":                  "8.002",
+				"role":                     "",
+				"state":                    "",
+				"internal port cost":       "2000",
+				"admin int. cost":          "0",
+				"designated regional root": "",
+				"dsgn int. cost":           "0",
+				"designated bridge":        "8.000.44:38:39:00:00:01",
+				"designated port":          "",
+				"disputed":                 "no",
+			},
+		},
+		{
+			name: "values without padding",
+			output: `role:swp1 MSTI 1
+ port id 8.001 role Root
+ state learning internal port cost 2000
+`,
+			expected: map[string]string{
+				"port id":            "8.001",
+				"role":               "Root",
+				"state":              "learning",
+				"internal port cost": "2000",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values := parseTreePort([]byte(test.output))
+			if !reflect.DeepEqual(values, test.expected) {
+				t.Errorf("got %q, want %q", values, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseMstiList(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected []uint16
+		err      bool
+	}{
+		{output: "bridge list of known MSTIs:\n 0 1 2\n", expected: []uint16{0, 1, 2}},
+		{output: "bridge list of known MSTIs:\n 0\n", expected: []uint16{0}},
+		{output: "bridge list of known MSTIs:\n", expected: nil},
+		{output: "bridge list of known MSTIs:\n 0 x\n", err: true},
+		{output: "Couldn't find bridge with name bridge\n", err: true},
+	}
+
+	for _, test := range tests {
+		mstis, err := parseMstiList([]byte(test.output))
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: parsing succeeded, want an error", test.output)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.output, err)
+			continue
+		}
+		if !reflect.DeepEqual(mstis, test.expected) {
+			t.Errorf("%q: got %v, want %v", test.output, mstis, test.expected)
+		}
+	}
+}
+
+func TestParseAllocations(t *testing.T) {
+	vid2fid, err := parseAllocations([]byte(`bridge VID-to-FID allocation table:
+  FID 0: 1-99,101-4094
+  FID 1: 100
+  FID 2:
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vid2fid) != 4094 {
+		t.Errorf("got %d VLANs, want 4094", len(vid2fid))
+	}
+	for vid, fid := range map[uint16]uint16{1: 0, 99: 0, 100: 1, 101: 0, 4094: 0} {
+		if vid2fid[vid] != fid {
+			t.Errorf("got FID %d for VLAN %d, want %d", vid2fid[vid], vid, fid)
+		}
+	}
+
+	fid2msti, err := parseAllocations([]byte(`bridge FID-to-MSTID allocation table:
+  MSTID 0: 0,3-4095
+  MSTID 1: 1
+  MSTID 2: 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for fid, msti := range map[uint16]uint16{0: 0, 1: 1, 2: 2, 3: 0, 4095: 0} {
+		if fid2msti[fid] != msti {
+			t.Errorf("got MSTI %d for FID %d, want %d", fid2msti[fid], fid, msti)
+		}
+	}
+
+	_, err = parseAllocations([]byte("  FID 0: 1-x\n"))
+	if err == nil {
+		t.Error("parsing an invalid range succeeded")
+	}
+}
+
+func TestParseRanges(t *testing.T) {
+	tests := []struct {
+		ranges   string
+		expected []uint16
+		err      bool
+	}{
+		{ranges: "", expected: nil},
+		{ranges: "100", expected: []uint16{100}},
+		{ranges: "1-3,5", expected: []uint16{1, 2, 3, 5}},
+		{ranges: " 1-2 , 4 ", expected: []uint16{1, 2, 4}},
+		{ranges: "1-", err: true},
+		{ranges: "a-b", err: true},
+		{ranges: "65536", err: true},
+	}
+
+	for _, test := range tests {
+		ids, err := parseRanges(test.ranges)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: parsing succeeded, want an error", test.ranges)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.ranges, err)
+			continue
+		}
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("%q: got %v, want %v", test.ranges, ids, test.expected)
+		}
+	}
+
+	ids, err := parseRanges("1-99,101-4094")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 4093 || ids[0] != 1 || ids[98] != 99 || ids[99] != 101 || ids[4092] != 4094 {
+		t.Errorf("got %d ids from %d to %d, want 4093 from 1 to 4094 without 100", len(ids), ids[0], ids[len(ids)-1])
+	}
+}
+
+func TestFormatRanges(t *testing.T) {
+	tests := []struct {
+		ids      []uint16
+		expected string
+	}{
+		{ids: nil, expected: ""},
+		{ids: []uint16{100}, expected: "100"},
+		{ids: []uint16{5, 1, 2, 3}, expected: "1-3,5"},
+		{ids: []uint16{1, 3, 5}, expected: "1,3,5"},
+	}
+
+	for _, test := range tests {
+		ranges := formatRanges(test.ids)
+		if ranges != test.expected {
+			t.Errorf("%v: got %q, want %q", test.ids, ranges, test.expected)
+		}
+	}
+
+	ids, err := parseRanges("1-99,101-4094")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranges := formatRanges(ids); ranges != "1-99,101-4094" {
+		t.Errorf("got %q, want 1-99,101-4094", ranges)
+	}
+}
@@ -0,0 +1,63 @@
+package mstpd
+
+import (
+	"sync"
+	"time"
+)
+
+// rootChanges is the history of a bridge's designated root
+type rootChanges struct {
+	root       string
+	changes    uint64
+	lastChange time.Time
+}
+
+// rootTracker remembers the designated root of every bridge across
+// collections to count the root changes observed
+type rootTracker struct {
+	lock    sync.Mutex
+	bridges map[string]*rootChanges
+}
+
+func newRootTracker() *rootTracker {
+	return &rootTracker{
+		bridges: map[string]*rootChanges{},
+	}
+}
+
+// observe records the current designated root of bridge and returns its history
+func (t *rootTracker) observe(bridge string, root string) rootChanges {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	history, found := t.bridges[bridge]
+	if !found {
+		history = &rootChanges{
+			root: root,
+		}
+		t.bridges[bridge] = history
+	}
+	if history.root != root {
+		history.root = root
+		history.changes++
+		history.lastChange = time.Now()
+	}
+	return *history
+}
+
+// prune forgets the bridges not in bridges, so a bridge created again later
+// is not compared with the root it had before it was deleted
+func (t *rootTracker) prune(bridges []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	present := make(map[string]bool, len(bridges))
+	for _, bridge := range bridges {
+		present[bridge] = true
+	}
+	for bridge := range t.bridges {
+		if !present[bridge] {
+			delete(t.bridges, bridge)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package mstpd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRootTrackerObserve(t *testing.T) {
+	tracker := newRootTracker()
+
+	history := tracker.observe("bridge", "8000.000000000001")
+	if history.changes != 0 || !history.lastChange.IsZero() {
+		t.Fatalf("first root: got %d changes at %v, want none", history.changes, history.lastChange)
+	}
+
+	history = tracker.observe("bridge", "8000.000000000001")
+	if history.changes != 0 || !history.lastChange.IsZero() {
+		t.Fatalf("same root: got %d changes at %v, want none", history.changes, history.lastChange)
+	}
+
+	before := time.Now()
+	history = tracker.observe("bridge", "8000.000000000002")
+	if history.changes != 1 {
+		t.Fatalf("changed root: got %d changes, want 1", history.changes)
+	}
+	if history.lastChange.Before(before) || history.lastChange.After(time.Now()) {
+		t.Fatalf("changed root: got last change %v, want the time of the observation", history.lastChange)
+	}
+	firstChange := history.lastChange
+
+	time.Sleep(time.Millisecond)
+	history = tracker.observe("bridge", "8000.000000000001")
+	if history.changes != 2 {
+		t.Fatalf("previous root again: got %d changes, want 2", history.changes)
+	}
+	if !history.lastChange.After(firstChange) {
+		t.Fatalf("previous root again: got last change %v, want after %v", history.lastChange, firstChange)
+	}
+}
+
+func TestRootTrackerPrune(t *testing.T) {
+	tracker := newRootTracker()
+	tracker.observe("bridge", "8000.000000000001")
+	tracker.observe("bridge", "8000.000000000002")
+	tracker.observe("br1", "8000.000000000001")
+
+	tracker.prune([]string{"br1"})
+	if _, found := tracker.bridges["bridge"]; found {
+		t.Error("removed bridge is still tracked")
+	}
+	if _, found := tracker.bridges["br1"]; !found {
+		t.Error("present bridge is no longer tracked")
+	}
+
+	// a bridge created again starts without history
+	history := tracker.observe("bridge", "8000.000000000003")
+	if history.changes != 0 {
+		t.Errorf("bridge created again: got %d changes, want 0", history.changes)
+	}
+}
@@ -1,27 +1,23 @@
 package mstpd
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"strconv"
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/vishvananda/netlink"
-	"os/exec"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
 )
 
 // GetBridges returns a list of the system's bridge interface names
 func GetBridges() ([]string, error) {
 	var res []string
 
-	handle, err := netlink.NewHandle()
+	linkList, err := sysroot.LinkList()
 	if err != nil {
-		return res, errors.Wrap(err, "Could not get netlink handle")
-	} else {
-		defer handle.Close()
-	}
-
-	linkList, err := handle.LinkList()
-	if err != nil {
-		return res, errors.Wrap(err, "Could not get system link list")
+		return res, err
 	}
 
 	for _, link := range linkList {
@@ -35,19 +31,14 @@ func GetBridges() ([]string, error) {
 }
 
 // ShowPortDetail executes and parses "mstpctl showportdetails <bridge> json"
-func ShowPortDetail(mstpctlPath string, bridgeName string) (ShowPortDetailResult, error) {
-	cmd := exec.Command(mstpctlPath, "showportdetail", bridgeName, "json")
+func ShowPortDetail(ctx context.Context, mstpctlPath string, bridgeName string) (ShowPortDetailResult, error) {
 	res := ShowPortDetailResult{}
-	var stdoutBuffer bytes.Buffer
-	var stderrBuffer bytes.Buffer
-	cmd.Stdout = &stdoutBuffer
-	cmd.Stderr = &stderrBuffer
-	err := cmd.Run()
+	stdout, stderr, err := sysroot.RunCommand(ctx, mstpctlPath, "showportdetail", bridgeName, "json")
 	if err != nil {
-		return res, errors.Wrapf(err, "Executing '%s showportdetail %s json' failed, stderr reads: %s", mstpctlPath, bridgeName, stderrBuffer.String())
+		return res, errors.Wrapf(err, "Executing '%s showportdetail %s json' failed, stderr reads: %s", mstpctlPath, bridgeName, stderr)
 	}
 
-	err = json.Unmarshal(stdoutBuffer.Bytes(), &res)
+	err = json.Unmarshal(stdout, &res)
 	if err != nil {
 		return res, errors.Wrap(err, "JSON unmarshal failed")
 	}
@@ -55,10 +46,74 @@ func ShowPortDetail(mstpctlPath string, bridgeName string) (ShowPortDetailResult
 	return res, nil
 }
 
-// BoolToFloat64 returns 1 for true and 0 for false
-func BoolToFloat64(b bool) float64 {
-	if b {
-		return 1.0
+// ShowBridge executes and parses "mstpctl showbridge <bridge> json"
+func ShowBridge(ctx context.Context, mstpctlPath string, bridgeName string) (ShowBridgeResult, error) {
+	res := ShowBridgeResult{}
+	stdout, stderr, err := sysroot.RunCommand(ctx, mstpctlPath, "showbridge", bridgeName, "json")
+	if err != nil {
+		return res, errors.Wrapf(err, "Executing '%s showbridge %s json' failed, stderr reads: %s", mstpctlPath, bridgeName, stderr)
+	}
+
+	err = json.Unmarshal(stdout, &res)
+	if err != nil {
+		return res, errors.Wrap(err, "JSON unmarshal failed")
+	}
+
+	return res, nil
+}
+
+// ShowMstiList executes and parses "mstpctl showmstilist <bridge>"
+func ShowMstiList(ctx context.Context, mstpctlPath string, bridgeName string) ([]uint16, error) {
+	stdout, err := runMstpctl(ctx, mstpctlPath, "showmstilist", bridgeName)
+	if err != nil {
+		return nil, err
+	}
+	return parseMstiList(stdout)
+}
+
+// ShowTreePort executes and parses "mstpctl showtreeport <bridge> <port> <msti>"
+func ShowTreePort(ctx context.Context, mstpctlPath string, bridgeName string, portName string, msti uint16) (*TreePortDetails, error) {
+	stdout, err := runMstpctl(ctx, mstpctlPath, "showtreeport", bridgeName, portName, strconv.Itoa(int(msti)))
+	if err != nil {
+		return nil, err
+	}
+	values := parseTreePort(stdout)
+	return &TreePortDetails{
+		BridgeName: bridgeName,
+		PortName:   portName,
+		MSTI:       msti,
+		Role:       values["role"],
+		State:      values["state"],
+	}, nil
+}
+
+// ShowMstiVlans executes "mstpctl showvid2fid <bridge>" and "mstpctl showfid2mstid <bridge>"
+// and returns the VLANs of every MSTI
+func ShowMstiVlans(ctx context.Context, mstpctlPath string, bridgeName string) (map[uint16]string, error) {
+	stdout, err := runMstpctl(ctx, mstpctlPath, "showvid2fid", bridgeName)
+	if err != nil {
+		return nil, err
+	}
+	vid2fid, err := parseAllocations(stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err = runMstpctl(ctx, mstpctlPath, "showfid2mstid", bridgeName)
+	if err != nil {
+		return nil, err
+	}
+	fid2msti, err := parseAllocations(stdout)
+	if err != nil {
+		return nil, err
+	}
+	return MstiVlans(vid2fid, fid2msti), nil
+}
+
+func runMstpctl(ctx context.Context, mstpctlPath string, args ...string) ([]byte, error) {
+	stdout, stderr, err := sysroot.RunCommand(ctx, mstpctlPath, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Executing '%s %s' failed, stderr reads: %s", mstpctlPath, strings.Join(args, " "), stderr)
 	}
-	return 0
+	return stdout, nil
 }
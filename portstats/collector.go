@@ -0,0 +1,136 @@
+package portstats
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const prefix = "portstats_"
+
+var (
+	counters        = flag.String("collector.portstats.counters", strings.Join(defaultCounters, ","), "Comma separated list of normalized counters to expose")
+	interfaceFilter = util.InterfaceFilterFlags("portstats")
+
+	counterDescs = map[string]*prometheus.Desc{}
+)
+
+// Config configures the portstats collector
+type Config struct {
+	collector.Settings   `yaml:",inline"`
+	util.InterfaceFilter `yaml:",inline"`
+	Counters             []string `yaml:"counters"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	for _, counter := range c.Counters {
+		if _, found := counterDescs[counter]; !found {
+			return errors.Errorf("Unknown counter %s", counter)
+		}
+	}
+	return c.InterfaceFilter.Validate()
+}
+
+// Collector collects the hardware counters of swp ports as reported by `ethtool -S`
+type Collector struct {
+	interfaces *util.InterfaceMatcher
+	counters   map[string]bool
+}
+
+// NewCollector returns a new Collector instance exposing the given normalized counters
+func NewCollector(interfaces *util.InterfaceMatcher, counters []string) *Collector {
+	c := &Collector{
+		interfaces: interfaces,
+		counters:   map[string]bool{},
+	}
+	for _, counter := range counters {
+		c.counters[counter] = true
+	}
+	return c
+}
+
+func init() {
+	collector.Register("portstats", false, func() collector.Config {
+		return &Config{
+			InterfaceFilter: interfaceFilter(),
+			Counters:        util.SplitList(*counters),
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		config := cfg.(*Config)
+		interfaces, err := config.Matcher()
+		if err != nil {
+			return nil, err
+		}
+		return NewCollector(interfaces, config.Counters), nil
+	})
+
+	for _, definition := range counterDefinitions {
+		labels := append([]string{"interface"}, definition.labels...)
+		counterDescs[definition.name] = prometheus.NewDesc(prefix+definition.name+"_total", definition.help, labels, nil)
+	}
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range counterDescs {
+		ch <- desc
+	}
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	links, err := sysroot.LinkList()
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not get link list")
+		return
+	}
+
+	e, err := newEthtool()
+	if err != nil {
+		errorChan <- err
+		return
+	}
+	defer e.close()
+
+	for _, link := range links {
+		name := link.Attrs().Name
+		if link.Type() != "device" || !strings.HasPrefix(name, "swp") || !c.interfaces.Matches(name) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		err = c.collectForInterface(ctx, e, name, metrics)
+		if err != nil {
+			errorChan <- err
+		}
+	}
+}
+
+// Name returns the string "PortstatsCollector"
+func (*Collector) Name() string {
+	return "PortstatsCollector"
+}
+
+func (c *Collector) collectForInterface(ctx context.Context, e *ethtool, iface string, metrics chan<- prometheus.Metric) error {
+	driver, err := e.driver(ctx, iface)
+	if err != nil {
+		return errors.Wrapf(err, "Could not get driver of %s", iface)
+	}
+	stats, err := e.stats(ctx, iface)
+	if err != nil {
+		return errors.Wrapf(err, "Could not get statistics of %s", iface)
+	}
+
+	for _, sample := range normalize(iface, stats, mappingsForDriver(driver), c.counters) {
+		metrics <- prometheus.MustNewConstMetric(counterDescs[sample.counter], prometheus.CounterValue, sample.value, sample.labels...)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package portstats
+
+import (
+	"regexp"
+	"strings"
+)
+
+// counterDefinition describes a normalized counter, exported as
+// portstats_<name>_total with the labels interface and labels
+type counterDefinition struct {
+	name   string
+	help   string
+	labels []string
+}
+
+// counterDefinitions are the counters the driver specific statistics are
+// normalized to, these are the names used in the allowlist
+var counterDefinitions = []*counterDefinition{
+	{"prio_packets", "packets per priority", []string{"direction", "priority"}},
+	{"prio_bytes", "bytes per priority", []string{"direction", "priority"}},
+	{"pause_frames", "link level pause frames", []string{"direction"}},
+	{"pfc_frames", "priority flow control pause frames per priority", []string{"direction", "priority"}},
+	{"ecn_marked_packets", "packets ECN marked per traffic class", []string{"tc"}},
+	{"buffer_discards", "packets discarded due to lack of buffer space", []string{"direction"}},
+	{"tc_buffer_discards", "packets discarded due to lack of buffer space per traffic class", []string{"direction", "tc"}},
+	{"fec_corrected_codewords", "FEC codewords with errors corrected", nil},
+	{"fec_uncorrected_codewords", "FEC codewords with uncorrectable errors", nil},
+}
+
+// defaultCounters keeps the per priority counters out, as they add 32 series per port
+var defaultCounters = []string{"pause_frames", "pfc_frames", "ecn_marked_packets", "buffer_discards", "tc_buffer_discards", "fec_corrected_codewords", "fec_uncorrected_codewords"}
+
+// counterMapping maps a driver specific statistic to a normalized counter.
+// Label values are taken from labels or the pattern's named groups. Several
+// statistics mapping to the same series (e.g. per FEC lane) are summed up.
+type counterMapping struct {
+	pattern *regexp.Regexp
+	counter string
+	labels  map[string]string
+}
+
+func mapping(pattern string, counter string, labels map[string]string) *counterMapping {
+	return &counterMapping{
+		pattern: regexp.MustCompile(pattern),
+		counter: counter,
+		labels:  labels,
+	}
+}
+
+var (
+	rx = map[string]string{"direction": "rx"}
+	tx = map[string]string{"direction": "tx"}
+
+	// mlxswMappings are the statistics of the upstream mlxsw driver (NVIDIA Spectrum)
+	mlxswMappings = []*counterMapping{
+		mapping(`^rx_frames_prio_(?P<priority>\d)$`, "prio_packets", rx),
+		mapping(`^tx_frames_prio_(?P<priority>\d)$`, "prio_packets", tx),
+		mapping(`^rx_octets_prio_(?P<priority>\d)$`, "prio_bytes", rx),
+		mapping(`^tx_octets_prio_(?P<priority>\d)$`, "prio_bytes", tx),
+		mapping(`^a_pause_mac_ctrl_frames_received$`, "pause_frames", rx),
+		mapping(`^a_pause_mac_ctrl_frames_transmitted$`, "pause_frames", tx),
+		mapping(`^rx_pause_prio_(?P<priority>\d)$`, "pfc_frames", rx),
+		mapping(`^tx_pause_prio_(?P<priority>\d)$`, "pfc_frames", tx),
+		mapping(`^ecn_marked_tc_(?P<tc>\d)$`, "ecn_marked_packets", nil),
+		mapping(`^tc_no_buffer_discard_uc_tc_\d$`, "buffer_discards", tx),
+		mapping(`^tc_no_buffer_discard_uc_tc_(?P<tc>\d)$`, "tc_buffer_discards", tx),
+		mapping(`^rs_fec_corrected_blocks$`, "fec_corrected_codewords", nil),
+		mapping(`^rs_fec_uncorrectable_blocks$`, "fec_uncorrected_codewords", nil),
+		mapping(`^fc_fec_corrected_blocks_lane_\d$`, "fec_corrected_codewords", nil),
+		mapping(`^fc_fec_uncorrectable_blocks_lane_\d$`, "fec_uncorrected_codewords", nil),
+	}
+
+	// switchdMappings are the statistics of ports driven by Cumulus' switchd
+	// (Broadcom and Spectrum platforms). Its HwIf* statistics are per port
+	// only, so there are no per traffic class counters, and contain neither
+	// FEC nor ECN counters.
+	switchdMappings = []*counterMapping{
+		mapping(`^HwIfInPausePkt$`, "pause_frames", rx),
+		mapping(`^HwIfOutPausePkt$`, "pause_frames", tx),
+		mapping(`^HwIfInPfc(?P<priority>\d)Pkt$`, "pfc_frames", rx),
+		mapping(`^HwIfOutPfc(?P<priority>\d)Pkt$`, "pfc_frames", tx),
+		mapping(`^HwIfInBufferDrops$`, "buffer_discards", rx),
+		mapping(`^HwIfOutQDrops$`, "buffer_discards", tx),
+	}
+)
+
+// mappingsForDriver returns the mappings of the vendor whose driver drives the port
+func mappingsForDriver(driver string) []*counterMapping {
+	if strings.HasPrefix(driver, "mlxsw") {
+		return mlxswMappings
+	}
+	return switchdMappings
+}
+
+// labelValues returns the values of the counter's labels for the statistic
+// matched by match
+func (m *counterMapping) labelValues(definition *counterDefinition, match []string) []string {
+	values := make([]string, len(definition.labels))
+	for i, label := range definition.labels {
+		if value, found := m.labels[label]; found {
+			values[i] = value
+			continue
+		}
+		if index := m.pattern.SubexpIndex(label); index >= 0 {
+			values[i] = match[index]
+		}
+	}
+	return values
+}
+
+// counterSample is a series of a normalized counter
+type counterSample struct {
+	counter string
+	labels  []string
+	value   float64
+}
+
+// normalize maps the driver specific statistics of iface to the normalized
+// counters enabled in counters, keyed by counter and label values
+func normalize(iface string, stats map[string]uint64, mappings []*counterMapping, counters map[string]bool) map[string]*counterSample {
+	samples := map[string]*counterSample{}
+	for _, definition := range counterDefinitions {
+		if !counters[definition.name] {
+			continue
+		}
+		for _, mapping := range mappings {
+			if mapping.counter != definition.name {
+				continue
+			}
+			for stat, value := range stats {
+				match := mapping.pattern.FindStringSubmatch(stat)
+				if match == nil {
+					continue
+				}
+				labels := append([]string{iface}, mapping.labelValues(definition, match)...)
+				key := definition.name + "\xff" + strings.Join(labels, "\xff")
+				sample, found := samples[key]
+				if !found {
+					sample = &counterSample{counter: definition.name, labels: labels}
+					samples[key] = sample
+				}
+				sample.value += float64(value)
+			}
+		}
+	}
+	return samples
+}
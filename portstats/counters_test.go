@@ -0,0 +1,139 @@
+package portstats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func allCounters() map[string]bool {
+	counters := map[string]bool{}
+	for _, definition := range counterDefinitions {
+		counters[definition.name] = true
+	}
+	return counters
+}
+
+// normalized returns the samples of normalize as counter{label values} => value
+func normalized(stats map[string]uint64, mappings []*counterMapping, counters map[string]bool) map[string]float64 {
+	res := map[string]float64{}
+	for _, sample := range normalize("swp1", stats, mappings, counters) {
+		res[sample.counter+"{"+strings.Join(sample.labels, ",")+"}"] = sample.value
+	}
+	return res
+}
+
+func TestNormalizeMlxsw(t *testing.T) {
+	stats := map[string]uint64{
+		"rx_frames_prio_0":                    100,
+		"tx_octets_prio_3":                    2000,
+		"a_pause_mac_ctrl_frames_received":    1,
+		"a_pause_mac_ctrl_frames_transmitted": 2,
+		"rx_pause_prio_3":                     3,
+		"tx_pause_prio_3":                     4,
+		"ecn_marked_tc_1":                     5,
+		"tc_no_buffer_discard_uc_tc_0":        6,
+		"tc_no_buffer_discard_uc_tc_3":        7,
+		"rs_fec_corrected_blocks":             10,
+		"rs_fec_uncorrectable_blocks":         1,
+		"fc_fec_corrected_blocks_lane_0":      20,
+		"fc_fec_corrected_blocks_lane_1":      30,
+		"fc_fec_uncorrectable_blocks_lane_0":  2,
+		"fc_fec_uncorrectable_blocks_lane_3":  3,
+		"rx_octets_phy":                       12345,
+	}
+	expected := map[string]float64{
+		"prio_packets{swp1,rx,0}":       100,
+		"prio_bytes{swp1,tx,3}":         2000,
+		"pause_frames{swp1,rx}":         1,
+		"pause_frames{swp1,tx}":         2,
+		"pfc_frames{swp1,rx,3}":         3,
+		"pfc_frames{swp1,tx,3}":         4,
+		"ecn_marked_packets{swp1,1}":    5,
+		"buffer_discards{swp1,tx}":      13,
+		"tc_buffer_discards{swp1,tx,0}": 6,
+		"tc_buffer_discards{swp1,tx,3}": 7,
+		// RS-FEC and the per lane FC-FEC blocks are summed up
+		"fec_corrected_codewords{swp1}":   60,
+		"fec_uncorrected_codewords{swp1}": 6,
+	}
+	got := normalized(stats, mlxswMappings, allCounters())
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestNormalizeSwitchd(t *testing.T) {
+	stats := map[string]uint64{
+		"HwIfInOctets":      123456,
+		"HwIfInBufferDrops": 7,
+		"HwIfInPausePkt":    1,
+		"HwIfOutPausePkt":   2,
+		"HwIfInPfc3Pkt":     9,
+		"HwIfOutPfc3Pkt":    1,
+		"HwIfOutQDrops":     11,
+		"SoftOutDrops":      5,
+	}
+	expected := map[string]float64{
+		"pause_frames{swp1,rx}":    1,
+		"pause_frames{swp1,tx}":    2,
+		"pfc_frames{swp1,rx,3}":    9,
+		"pfc_frames{swp1,tx,3}":    1,
+		"buffer_discards{swp1,rx}": 7,
+		"buffer_discards{swp1,tx}": 11,
+	}
+	got := normalized(stats, switchdMappings, allCounters())
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestNormalizeAllowlist(t *testing.T) {
+	stats := map[string]uint64{
+		"tc_no_buffer_discard_uc_tc_0": 6,
+		"tc_no_buffer_discard_uc_tc_3": 7,
+		"rx_frames_prio_0":             100,
+	}
+	expected := map[string]float64{
+		"buffer_discards{swp1,tx}": 13,
+	}
+	got := normalized(stats, mlxswMappings, map[string]bool{"buffer_discards": true})
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestMappingsForDriver(t *testing.T) {
+	tests := []struct {
+		driver   string
+		expected []*counterMapping
+	}{
+		{driver: "mlxsw_spectrum", expected: mlxswMappings},
+		{driver: "mlxsw_spectrum2", expected: mlxswMappings},
+		{driver: "swp", expected: switchdMappings},
+		{driver: "", expected: switchdMappings},
+	}
+
+	for _, test := range tests {
+		mappings := mappingsForDriver(test.driver)
+		if len(mappings) == 0 || &mappings[0] != &test.expected[0] {
+			t.Errorf("%q: got the wrong mappings", test.driver)
+		}
+	}
+}
+
+func TestLabelValues(t *testing.T) {
+	definition := &counterDefinition{name: "pfc_frames", labels: []string{"direction", "priority"}}
+	m := mapping(`^rx_pause_prio_(?P<priority>\d)$`, "pfc_frames", rx)
+	values := m.labelValues(definition, m.pattern.FindStringSubmatch("rx_pause_prio_5"))
+	if !reflect.DeepEqual(values, []string{"rx", "5"}) {
+		t.Errorf("got %q, want [rx 5]", values)
+	}
+
+	// labels neither given nor matched are empty
+	m = mapping(`^tx_pause$`, "pfc_frames", nil)
+	values = m.labelValues(definition, m.pattern.FindStringSubmatch("tx_pause"))
+	if !reflect.DeepEqual(values, []string{"", ""}) {
+		t.Errorf("got %q, want two empty values", values)
+	}
+}
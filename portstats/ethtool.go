@@ -0,0 +1,94 @@
+package portstats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	ethtoollib "github.com/safchain/ethtool"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+)
+
+// ethtool reads the driver specific statistics of interfaces (`ethtool -S`)
+// through the SIOCETHTOOL ioctl. In sysroot mode the recorded output of
+// `ethtool -i` and `ethtool -S` is parsed instead.
+type ethtool struct {
+	handle *ethtoollib.Ethtool
+}
+
+func newEthtool() (*ethtool, error) {
+	if sysroot.Enabled() {
+		return &ethtool{}, nil
+	}
+	handle, err := ethtoollib.NewEthtool()
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not open ethtool socket")
+	}
+	return &ethtool{handle: handle}, nil
+}
+
+func (e *ethtool) close() {
+	if e.handle != nil {
+		e.handle.Close()
+	}
+}
+
+// driver returns the name of the interface's driver
+func (e *ethtool) driver(ctx context.Context, iface string) (string, error) {
+	if sysroot.Enabled() {
+		stdout, _, err := sysroot.RunCommand(ctx, "ethtool", "-i", iface)
+		if err != nil {
+			return "", err
+		}
+		values := parseColonSeparated(stdout)
+		return values["driver"], nil
+	}
+
+	driver, err := e.handle.DriverName(iface)
+	if err != nil {
+		return "", errors.Wrapf(err, "Could not get driver info of %s", iface)
+	}
+	return driver, nil
+}
+
+// stats returns the interface's statistics indexed by name
+func (e *ethtool) stats(ctx context.Context, iface string) (map[string]uint64, error) {
+	if sysroot.Enabled() {
+		stdout, _, err := sysroot.RunCommand(ctx, "ethtool", "-S", iface)
+		if err != nil {
+			return nil, err
+		}
+		stats := map[string]uint64{}
+		for name, value := range parseColonSeparated(stdout) {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			stats[name] = parsed
+		}
+		return stats, nil
+	}
+
+	stats, err := e.handle.Stats(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not get statistics of %s", iface)
+	}
+	return stats, nil
+}
+
+// parseColonSeparated parses "name: value" lines as printed by ethtool
+func parseColonSeparated(output []byte) map[string]string {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		name, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return values
+}
@@ -0,0 +1,119 @@
+package ptm
+
+import (
+	"context"
+	"flag"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+const prefix = "ptm_"
+
+var (
+	ptmctlPath = flag.String("collector.ptm.ptmctl-path", "/usr/bin/ptmctl", "ptmctl binary path")
+
+	cablingPassDesc       *prometheus.Desc
+	cablingStatusInfoDesc *prometheus.Desc
+	neighborInfoDesc      *prometheus.Desc
+	bfdUpDesc             *prometheus.Desc
+	bfdStatusInfoDesc     *prometheus.Desc
+)
+
+// Config configures the ptm collector
+type Config struct {
+	collector.Settings `yaml:",inline"`
+	PtmctlPath         string `yaml:"ptmctl_path"`
+}
+
+// Validate implements collector.Validator
+func (c *Config) Validate() error {
+	if c.PtmctlPath == "" {
+		return errors.New("ptmctl_path must not be empty")
+	}
+	return nil
+}
+
+// Collector collects cabling verification and BFD metrics exposed by ptmctl
+type Collector struct {
+	ptmctlPath string
+}
+
+// NewCollector returns a new Collector instance
+func NewCollector(ptmctlPath string) *Collector {
+	return &Collector{
+		ptmctlPath: ptmctlPath,
+	}
+}
+
+func init() {
+	collector.Register("ptm", false, func() collector.Config {
+		return &Config{
+			PtmctlPath: *ptmctlPath,
+		}
+	}, func(cfg collector.Config) (collector.Collector, error) {
+		return NewCollector(cfg.(*Config).PtmctlPath), nil
+	})
+
+	labels := []string{"interface"}
+	cablingPassDesc = prometheus.NewDesc(prefix+"cabling_pass_bool", "actual neighbor matches the topology file", labels, nil)
+	cablingStatusInfoDesc = prometheus.NewDesc(prefix+"cabling_status_info", "cabling status", append(labels, "status"), nil)
+	neighborInfoDesc = prometheus.NewDesc(prefix+"neighbor_info", "expected and actual neighbor", append(labels, "expected_neighbor", "actual_neighbor"), nil)
+
+	bfdLabels := append(labels, "peer", "local", "type")
+	bfdUpDesc = prometheus.NewDesc(prefix+"bfd_up_bool", "BFD session up", bfdLabels, nil)
+	bfdStatusInfoDesc = prometheus.NewDesc(prefix+"bfd_status_info", "BFD session state and diagnostics", append(bfdLabels, "state", "diagnostics"), nil)
+}
+
+// Describe implements collector.Collector interface's Describe function
+func (*Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cablingPassDesc
+	ch <- cablingStatusInfoDesc
+	ch <- neighborInfoDesc
+	ch <- bfdUpDesc
+	ch <- bfdStatusInfoDesc
+}
+
+// Collect implements collector.Collector interface's Collect function
+func (c *Collector) Collect(ctx context.Context, metrics chan<- prometheus.Metric, errorChan chan<- error) {
+	result, err := ShowStatus(ctx, c.ptmctlPath)
+	if err != nil {
+		errorChan <- errors.Wrap(err, "Could not retrieve PTM status")
+		return
+	}
+
+	// a port with several BFD sessions is listed once per session
+	seenPorts := map[string]bool{}
+	for _, port := range result {
+		if port == nil || port.Port == "" {
+			continue
+		}
+		if port.HasCablingStatus() && !seenPorts[port.Port] {
+			seenPorts[port.Port] = true
+			collectCablingForPort(port, metrics)
+		}
+		if port.HasBfdSession() {
+			collectBfdForPort(port, metrics)
+		}
+	}
+}
+
+// Name returns the string "PtmCollector"
+func (*Collector) Name() string {
+	return "PtmCollector"
+}
+
+func collectCablingForPort(port *Port, metrics chan<- prometheus.Metric) {
+	metrics <- prometheus.MustNewConstMetric(cablingPassDesc, prometheus.GaugeValue, util.BoolToFloat64(port.CablingStatus == "pass"), port.Port)
+	metrics <- prometheus.MustNewConstMetric(cablingStatusInfoDesc, prometheus.GaugeValue, 1.0, port.Port, port.CablingStatus)
+	metrics <- prometheus.MustNewConstMetric(neighborInfoDesc, prometheus.GaugeValue, 1.0, port.Port, port.ExpectedNbr, port.ActualNbr)
+}
+
+func collectBfdForPort(port *Port, metrics chan<- prometheus.Metric) {
+	labels := []string{port.Port, port.BfdPeer, port.BfdLocal, port.BfdType}
+	metrics <- prometheus.MustNewConstMetric(bfdUpDesc, prometheus.GaugeValue, util.BoolToFloat64(port.BfdState == "up"), labels...)
+	statusLabels := append(labels, port.BfdState, port.BfdDiagnostics)
+	metrics <- prometheus.MustNewConstMetric(bfdStatusInfoDesc, prometheus.GaugeValue, 1.0, statusLabels...)
+}
@@ -0,0 +1,31 @@
+package ptm
+
+// notApplicable is used by ptmctl for values not available, e.g. the BFD
+// status of a port without BFD session
+const notApplicable = "N/A"
+
+// PtmctlResult stores the parsed output of `ptmctl -j`, indexed by a row number
+type PtmctlResult map[string]*Port
+
+// Port stores the cabling and BFD status of a single port
+type Port struct {
+	Port           string `json:"port"`
+	CablingStatus  string `json:"cbl status"`
+	ExpectedNbr    string `json:"exp nbr"`
+	ActualNbr      string `json:"act nbr"`
+	BfdState       string `json:"state"`
+	BfdPeer        string `json:"peer"`
+	BfdLocal       string `json:"local"`
+	BfdType        string `json:"type"`
+	BfdDiagnostics string `json:"diag"`
+}
+
+// HasCablingStatus returns true if the port is part of the topology file
+func (p *Port) HasCablingStatus() bool {
+	return p.CablingStatus != "" && p.CablingStatus != notApplicable
+}
+
+// HasBfdSession returns true if a BFD session is configured on the port
+func (p *Port) HasBfdSession() bool {
+	return p.BfdState != "" && p.BfdState != notApplicable
+}
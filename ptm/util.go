@@ -0,0 +1,25 @@
+package ptm
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"gitlab.com/wobcom/cumulus-exporter/sysroot"
+)
+
+// ShowStatus executes and parses "ptmctl -j"
+func ShowStatus(ctx context.Context, ptmctlPath string) (PtmctlResult, error) {
+	stdout, stderr, err := sysroot.RunCommand(ctx, ptmctlPath, "-j")
+	if err != nil {
+		return nil, errors.Wrapf(err, "Executing '%s -j' failed, stderr reads: %s", ptmctlPath, stderr)
+	}
+
+	res := PtmctlResult{}
+	err = json.Unmarshal(stdout, &res)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON unmarshal failed")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,234 @@
+package sysroot
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// recordedLink is a single entry of `ip -details -json link show`
+type recordedLink struct {
+	Index     int    `json:"ifindex"`
+	Name      string `json:"ifname"`
+	MTU       int    `json:"mtu"`
+	OperState string `json:"operstate"`
+	Alias     string `json:"ifalias"`
+	Master    string `json:"master"`
+	Address   string `json:"address"`
+	LinkInfo  struct {
+		Kind     string `json:"info_kind"`
+		InfoData struct {
+			VlanFiltering *int `json:"vlan_filtering"`
+		} `json:"info_data"`
+	} `json:"linkinfo"`
+	// ProtoDownReasons is only present if the link is protodown for any reason
+	ProtoDownReasons []string `json:"proto_down_reason"`
+}
+
+// LinkList returns the system's links. In sysroot mode they are read from links.json.
+func LinkList() ([]netlink.Link, error) {
+	if !Enabled() {
+		handle, err := netlink.NewHandle()
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not get netlink handle")
+		}
+		defer handle.Close()
+
+		links, err := handle.LinkList()
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not get system link list")
+		}
+		return links, nil
+	}
+
+	recordedLinks, err := readRecordedLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	indexByName := map[string]int{}
+	for _, recorded := range recordedLinks {
+		indexByName[recorded.Name] = recorded.Index
+	}
+
+	links := make([]netlink.Link, 0, len(recordedLinks))
+	for _, recorded := range recordedLinks {
+		attrs := netlink.NewLinkAttrs()
+		attrs.Index = recorded.Index
+		attrs.Name = recorded.Name
+		attrs.MTU = recorded.MTU
+		attrs.Alias = recorded.Alias
+		attrs.OperState = parseOperState(recorded.OperState)
+		attrs.MasterIndex = indexByName[recorded.Master]
+		attrs.HardwareAddr, _ = net.ParseMAC(recorded.Address)
+		link := newLink(recorded.LinkInfo.Kind, attrs)
+		if bridge, ok := link.(*netlink.Bridge); ok && recorded.LinkInfo.InfoData.VlanFiltering != nil {
+			vlanFiltering := *recorded.LinkInfo.InfoData.VlanFiltering != 0
+			bridge.VlanFiltering = &vlanFiltering
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func readRecordedLinks() ([]recordedLink, error) {
+	fileName := filepath.Join(*root, "links.json")
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not read recorded link list '%s'", fileName)
+	}
+	var recordedLinks []recordedLink
+	err = json.Unmarshal(data, &recordedLinks)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not parse recorded link list '%s'", fileName)
+	}
+	return recordedLinks, nil
+}
+
+// ProtoDownReasons returns the names of the reasons links are protodown for,
+// keyed by link name. Links without any reason set are omitted. In sysroot
+// mode the reasons are read from links.json.
+func ProtoDownReasons() (map[string][]string, error) {
+	if Enabled() {
+		recordedLinks, err := readRecordedLinks()
+		if err != nil {
+			return nil, err
+		}
+		reasons := map[string][]string{}
+		for _, recorded := range recordedLinks {
+			if len(recorded.ProtoDownReasons) > 0 {
+				reasons[recorded.Name] = recorded.ProtoDownReasons
+			}
+		}
+		return reasons, nil
+	}
+
+	// the netlink library does not parse IFLA_PROTO_DOWN_REASON, so the links
+	// are dumped here
+	req := nl.NewNetlinkRequest(unix.RTM_GETLINK, unix.NLM_F_DUMP)
+	req.AddData(nl.NewIfInfomsg(unix.AF_UNSPEC))
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWLINK)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not dump links")
+	}
+	return parseProtoDownReasons(msgs, protoDownReasonNames())
+}
+
+// parseProtoDownReasons returns the reasons set in the RTM_NEWLINK messages
+// msgs, named after names or the bit number if the reason has no name
+func parseProtoDownReasons(msgs [][]byte, names map[int]string) (map[string][]string, error) {
+	reasons := map[string][]string{}
+	for _, msg := range msgs {
+		if len(msg) < unix.SizeofIfInfomsg {
+			continue
+		}
+		attrs, err := nl.ParseRouteAttr(msg[unix.SizeofIfInfomsg:])
+		if err != nil {
+			return nil, errors.Wrap(err, "Could not parse link attributes")
+		}
+		var name string
+		var value uint32
+		for _, attr := range attrs {
+			// the kernel sets NLA_F_NESTED on IFLA_PROTO_DOWN_REASON
+			switch attr.Attr.Type & nl.NLA_TYPE_MASK {
+			case unix.IFLA_IFNAME:
+				name = strings.TrimRight(string(attr.Value), "\x00")
+			case unix.IFLA_PROTO_DOWN_REASON:
+				value = parseProtoDownReasonValue(attr.Value)
+			}
+		}
+		for bit := 0; bit < 32; bit++ {
+			if value&(1<<bit) == 0 {
+				continue
+			}
+			reason, found := names[bit]
+			if !found {
+				reason = strconv.Itoa(bit)
+			}
+			reasons[name] = append(reasons[name], reason)
+		}
+	}
+	return reasons, nil
+}
+
+func parseProtoDownReasonValue(data []byte) uint32 {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return 0
+	}
+	for _, attr := range attrs {
+		if attr.Attr.Type&nl.NLA_TYPE_MASK == unix.IFLA_PROTO_DOWN_REASON_VALUE && len(attr.Value) >= 4 {
+			return nl.NativeEndian().Uint32(attr.Value)
+		}
+	}
+	return 0
+}
+
+// protoDownReasonNames reads the reason names iproute2 uses from
+// /etc/iproute2/protodown_reasons.d/*.conf, lines of the form "<bit> <name>"
+func protoDownReasonNames() map[int]string {
+	names := map[int]string{}
+	files, _ := filepath.Glob(Path("/etc/iproute2/protodown_reasons.d/*.conf"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			bit, err := strconv.Atoi(fields[0])
+			if err != nil || bit < 0 || bit > 31 {
+				continue
+			}
+			names[bit] = fields[1]
+		}
+	}
+	return names
+}
+
+func newLink(kind string, attrs netlink.LinkAttrs) netlink.Link {
+	switch kind {
+	case "bridge":
+		return &netlink.Bridge{LinkAttrs: attrs}
+	case "bond":
+		return &netlink.Bond{LinkAttrs: attrs}
+	case "vlan":
+		return &netlink.Vlan{LinkAttrs: attrs}
+	case "vxlan":
+		return &netlink.Vxlan{LinkAttrs: attrs}
+	case "vrf":
+		return &netlink.Vrf{LinkAttrs: attrs}
+	case "":
+		return &netlink.Device{LinkAttrs: attrs}
+	}
+	return &netlink.GenericLink{LinkAttrs: attrs, LinkType: kind}
+}
+
+func parseOperState(state string) netlink.LinkOperState {
+	switch state {
+	case "UP":
+		return netlink.OperUp
+	case "DOWN":
+		return netlink.OperDown
+	case "LOWERLAYERDOWN":
+		return netlink.OperLowerLayerDown
+	case "DORMANT":
+		return netlink.OperDormant
+	case "NOTPRESENT":
+		return netlink.OperNotPresent
+	case "TESTING":
+		return netlink.OperTesting
+	}
+	return netlink.OperUnknown
+}
@@ -0,0 +1,69 @@
+package sysroot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// newLinkMessage serializes an RTM_NEWLINK message the way the kernel sends
+// it, with the protodown reason attribute if reason is not 0
+func newLinkMessage(name string, reason uint32) []byte {
+	b := nl.NewIfInfomsg(unix.AF_UNSPEC).Serialize()
+	b = append(b, nl.NewRtAttr(unix.IFLA_IFNAME, nl.ZeroTerminated(name)).Serialize()...)
+	if reason != 0 {
+		attr := nl.NewRtAttr(unix.IFLA_PROTO_DOWN_REASON|unix.NLA_F_NESTED, nil)
+		attr.AddRtAttr(unix.IFLA_PROTO_DOWN_REASON_MASK, nl.Uint32Attr(0xffffffff))
+		attr.AddRtAttr(unix.IFLA_PROTO_DOWN_REASON_VALUE, nl.Uint32Attr(reason))
+		b = append(b, attr.Serialize()...)
+	}
+	return b
+}
+
+func TestParseProtoDownReasons(t *testing.T) {
+	msgs := [][]byte{
+		newLinkMessage("swp1", 1<<0|1<<2),
+		newLinkMessage("swp2", 0),
+		newLinkMessage("swp3", 1<<31),
+	}
+	reasons, err := parseProtoDownReasons(msgs, map[int]string{0: "clag", 31: "frr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"swp1": {"clag", "2"},
+		"swp3": {"frr"},
+	}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("got reasons %v, want %v", reasons, expected)
+	}
+}
+
+func TestProtoDownReasonNames(t *testing.T) {
+	previous := *root
+	*root = t.TempDir()
+	defer func() {
+		*root = previous
+	}()
+
+	dir := filepath.Join(*root, "etc/iproute2/protodown_reasons.d")
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := "# reasons of the routing suite\n0 clag\n31\tfrr\n32 invalid\nx invalid\n"
+	err = os.WriteFile(filepath.Join(dir, "cumulus.conf"), []byte(conf), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := protoDownReasonNames()
+	expected := map[int]string{0: "clag", 31: "frr"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("got names %v, want %v", names, expected)
+	}
+}
@@ -0,0 +1,65 @@
+// Package sysroot allows running the exporter against data recorded on a
+// device instead of the live system. The sysroot directory contains
+//
+//   - the files the collectors read, at their usual path (e.g. cumulus/switchd/run/...)
+//   - commands/<command>: the recorded stdout of a command, see CommandFile
+//   - links.json: the output of `ip -details -json link show`
+package sysroot
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var root = flag.String("sysroot", "", "Directory containing data recorded on a device (switchd tree, command outputs, links.json) to run the collectors against instead of the live system")
+
+// Enabled returns true if the exporter runs against a sysroot directory
+func Enabled() bool {
+	return *root != ""
+}
+
+// Path returns path below the sysroot directory, or path itself if no sysroot is used
+func Path(path string) string {
+	if !Enabled() {
+		return path
+	}
+	return filepath.Join(*root, path)
+}
+
+// CommandFile returns the file the output of a command is read from in
+// sysroot mode. It is named after the binary's base name and its arguments
+// joined by underscores, e.g. commands/mstpctl_showportdetail_br0_json.
+// Slashes and spaces within arguments are replaced by underscores as well,
+// e.g. commands/vtysh_-c_show_bgp_vrf_all_summary_json.
+func CommandFile(name string, args ...string) string {
+	parts := append([]string{filepath.Base(name)}, args...)
+	fileName := strings.NewReplacer("/", "_", " ", "_").Replace(strings.Join(parts, "_"))
+	return filepath.Join(*root, "commands", fileName)
+}
+
+// RunCommand executes name with args and returns its stdout and stderr. In
+// sysroot mode the recorded output is returned instead.
+func RunCommand(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+	if Enabled() {
+		stdout, err := os.ReadFile(CommandFile(name, args...))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Could not read recorded output of '%s %s'", name, strings.Join(args, " "))
+		}
+		return stdout, nil, nil
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdoutBuffer bytes.Buffer
+	var stderrBuffer bytes.Buffer
+	cmd.Stdout = &stdoutBuffer
+	cmd.Stderr = &stderrBuffer
+	err := cmd.Run()
+	return stdoutBuffer.Bytes(), stderrBuffer.Bytes(), err
+}
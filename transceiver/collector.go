@@ -0,0 +1,49 @@
+package transceiver
+
+import (
+	"flag"
+
+	"github.com/wobcom/transceiver-exporter/transceiver-collector"
+	"gitlab.com/wobcom/cumulus-exporter/collector"
+	"gitlab.com/wobcom/cumulus-exporter/util"
+)
+
+var (
+	collectInterfaceFeatures = flag.Bool("collector.transceiver.interface-features", false, "Collect interface features (results in many time series)")
+	interfaceFilter          = util.InterfaceFilterFlags("transceiver")
+)
+
+// Config configures the transceiver collector
+type Config struct {
+	collector.Settings   `yaml:",inline"`
+	util.InterfaceFilter `yaml:",inline"`
+	InterfaceFeatures    bool `yaml:"interface_features"`
+}
+
+func init() {
+	for _, option := range []string{"interface-features", "exclude-interfaces", "include-interfaces", "exclude-interfaces-regex", "include-interfaces-regex"} {
+		collector.DeprecatedAlias("collectors.transceiver."+option, "collector.transceiver."+option)
+	}
+	collector.Register("transceiver", false, func() collector.Config {
+		return &Config{
+			InterfaceFilter:   interfaceFilter(),
+			InterfaceFeatures: *collectInterfaceFeatures,
+		}
+	}, NewCollector)
+}
+
+// NewCollector returns the transceiver-exporter's collector (rx / tx power,
+// temperatures, etc.) configured by cfg
+func NewCollector(cfg collector.Config) (collector.Collector, error) {
+	config := cfg.(*Config)
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	includeIfaceRegex, _ := util.CompileRegex(config.IncludeInterfacesRegex)
+	excludeIfaceRegex, _ := util.CompileRegex(config.ExcludeInterfacesRegex)
+
+	c := transceivercollector.NewCollector(config.ExcludeInterfaces, config.IncludeInterfaces, excludeIfaceRegex, includeIfaceRegex, true, config.InterfaceFeatures, false)
+	return collector.WrapLegacy(c), nil
+}
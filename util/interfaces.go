@@ -0,0 +1,110 @@
+package util
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// InterfaceFilter configures which interfaces a collector reports on. It is
+// embedded inline in the collector's configuration.
+type InterfaceFilter struct {
+	ExcludeInterfaces      []string `yaml:"exclude_interfaces"`
+	IncludeInterfaces      []string `yaml:"include_interfaces"`
+	ExcludeInterfacesRegex string   `yaml:"exclude_interfaces_regex"`
+	IncludeInterfacesRegex string   `yaml:"include_interfaces_regex"`
+}
+
+// InterfaceFilterFlags registers the flags collector.<name>.exclude-interfaces,
+// collector.<name>.include-interfaces and their -regex variants. The returned
+// function builds an InterfaceFilter from their values.
+func InterfaceFilterFlags(name string) func() InterfaceFilter {
+	excludeInterfaces := flag.String("collector."+name+".exclude-interfaces", "", "Comma seperated list of interfaces to exclude from scrape")
+	includeInterfaces := flag.String("collector."+name+".include-interfaces", "", "Comma seperated list of interfaces to include from scrape")
+	excludeInterfacesRegex := flag.String("collector."+name+".exclude-interfaces-regex", "", "Regex Expression for interfaces to exclude from scrape")
+	includeInterfacesRegex := flag.String("collector."+name+".include-interfaces-regex", "", "Regex Expression for interfaces to include from scrape")
+
+	return func() InterfaceFilter {
+		return InterfaceFilter{
+			ExcludeInterfaces:      SplitList(*excludeInterfaces),
+			IncludeInterfaces:      SplitList(*includeInterfaces),
+			ExcludeInterfacesRegex: *excludeInterfacesRegex,
+			IncludeInterfacesRegex: *includeInterfacesRegex,
+		}
+	}
+}
+
+// Validate checks the filter for errors
+func (f *InterfaceFilter) Validate() error {
+	_, err := f.Matcher()
+	return err
+}
+
+// Matcher compiles the filter
+func (f *InterfaceFilter) Matcher() (*InterfaceMatcher, error) {
+	if len(f.IncludeInterfaces) > 0 && len(f.ExcludeInterfaces) > 0 {
+		return nil, errors.New("Can't include and exclude interfaces at the same time")
+	}
+	includeRegex, err := CompileRegex(f.IncludeInterfacesRegex)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not compile include interface regex expression \"%s\"", f.IncludeInterfacesRegex)
+	}
+	excludeRegex, err := CompileRegex(f.ExcludeInterfacesRegex)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not compile exclude interface regex expression \"%s\"", f.ExcludeInterfacesRegex)
+	}
+	return &InterfaceMatcher{
+		exclude:      f.ExcludeInterfaces,
+		include:      f.IncludeInterfaces,
+		excludeRegex: excludeRegex,
+		includeRegex: includeRegex,
+	}, nil
+}
+
+// InterfaceMatcher is a compiled InterfaceFilter
+type InterfaceMatcher struct {
+	exclude      []string
+	include      []string
+	excludeRegex *regexp.Regexp
+	includeRegex *regexp.Regexp
+}
+
+// Matches returns true if the interface name passes the filter
+func (m *InterfaceMatcher) Matches(name string) bool {
+	if len(m.exclude) > 0 && contains(m.exclude, name) {
+		return false
+	}
+	if len(m.include) > 0 && !contains(m.include, name) {
+		return false
+	}
+	if m.excludeRegex != nil && m.excludeRegex.MatchString(name) {
+		return false
+	}
+	if m.includeRegex != nil && !m.includeRegex.MatchString(name) {
+		return false
+	}
+	return true
+}
+
+// SplitList splits a comma separated list, an empty list results in an empty slice
+func SplitList(list string) []string {
+	names := strings.Split(list, ",")
+	for index, name := range names {
+		names[index] = strings.TrimSpace(name)
+	}
+	if len(names) == 1 && names[0] == "" {
+		return []string{}
+	}
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
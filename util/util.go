@@ -3,6 +3,7 @@ package util
 import (
 	"github.com/pkg/errors"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -25,3 +26,19 @@ func ReadStringFromFile(filename string) (string, error) {
 	}
 	return strings.TrimSuffix(string(data), "\n"), nil
 }
+
+// CompileRegex compiles expr, an empty expression results in a nil regex
+func CompileRegex(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// BoolToFloat64 returns 1 for true and 0 for false
+func BoolToFloat64(b bool) float64 {
+	if b {
+		return 1.0
+	}
+	return 0
+}